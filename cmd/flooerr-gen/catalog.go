@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Catalog is the root of an error catalog file.
+type Catalog struct {
+	Package string  `json:"package"`
+	Codes   []Entry `json:"codes"`
+}
+
+// Entry declares a single error code.
+type Entry struct {
+	Code        string   `json:"code"`
+	Name        string   `json:"name"`
+	Message     string   `json:"message"`
+	Description string   `json:"description"`
+	Params      []Param  `json:"params"`
+	Context     []string `json:"context"`
+	HTTP        int      `json:"http"`
+	GRPC        string   `json:"grpc"`
+}
+
+// Param is a constructor parameter. Params referenced from the message as
+// {name} are formatted into it; params whose key is listed in the entry's
+// context are attached with WithContext.
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Key  string `json:"key"`
+}
+
+// grpcCodes maps google.golang.org/grpc/codes names to their values so the
+// generated code does not need to import grpc.
+var grpcCodes = map[string]uint32{
+	"OK":                 0,
+	"Canceled":           1,
+	"Unknown":            2,
+	"InvalidArgument":    3,
+	"DeadlineExceeded":   4,
+	"NotFound":           5,
+	"AlreadyExists":      6,
+	"PermissionDenied":   7,
+	"ResourceExhausted":  8,
+	"FailedPrecondition": 9,
+	"Aborted":            10,
+	"OutOfRange":         11,
+	"Unimplemented":      12,
+	"Internal":           13,
+	"Unavailable":        14,
+	"DataLoss":           15,
+	"Unauthenticated":    16,
+}
+
+// reservedParams are the identifiers the generated constructors use
+// besides their params: the msg local and the fmt and flooerr packages.
+var reservedParams = map[string]bool{"msg": true, "fmt": true, "flooerr": true}
+
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// loadCatalog reads a catalog from a .json, .yaml or .yml file.
+func loadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+	case ".yaml", ".yml":
+		doc, err := parseYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := yamlCodes(doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported catalog extension, want .json, .yaml or .yml", path)
+	}
+
+	var catalog Catalog
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &catalog, nil
+}
+
+// yamlCodes turns codes written as plain integers, such as 40401, back into
+// the strings the catalog declares. Other non-string codes are rejected.
+func yamlCodes(doc any) error {
+	root, _ := doc.(map[string]any)
+	codes, _ := root["codes"].([]any)
+	for i, code := range codes {
+		entry, ok := code.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch value := entry["code"].(type) {
+		case string, nil:
+		case int64:
+			entry["code"] = strconv.FormatInt(value, 10)
+		default:
+			return fmt.Errorf("codes[%d]: code must be a string, got %v; quote it", i, value)
+		}
+	}
+	return nil
+}
+
+// normalize fills defaults and validates the catalog.
+func (c *Catalog) normalize() error {
+	if c.Package != "" && !token.IsIdentifier(c.Package) {
+		return fmt.Errorf("invalid package name %q", c.Package)
+	}
+	if len(c.Codes) == 0 {
+		return fmt.Errorf("catalog declares no codes")
+	}
+
+	codes := make(map[string]bool)
+	names := make(map[string]bool)
+	for i := range c.Codes {
+		entry := &c.Codes[i]
+		if entry.Code == "" {
+			return fmt.Errorf("codes[%d]: code is required", i)
+		}
+		if codes[entry.Code] {
+			return fmt.Errorf("code %s: declared more than once", entry.Code)
+		}
+		codes[entry.Code] = true
+
+		if entry.Name == "" {
+			entry.Name = identFromCode(entry.Code)
+		}
+		if !token.IsIdentifier(entry.Name) || !token.IsExported(entry.Name) {
+			return fmt.Errorf("code %s: name %q is not an exported Go identifier", entry.Code, entry.Name)
+		}
+		if names[entry.Name] {
+			return fmt.Errorf("code %s: name %s declared more than once", entry.Code, entry.Name)
+		}
+		names[entry.Name] = true
+
+		if entry.Message == "" {
+			return fmt.Errorf("code %s: message is required", entry.Code)
+		}
+		if err := entry.normalizeParams(); err != nil {
+			return fmt.Errorf("code %s: %w", entry.Code, err)
+		}
+		if entry.HTTP != 0 && (entry.HTTP < 100 || entry.HTTP > 599) {
+			return fmt.Errorf("code %s: invalid HTTP status %d", entry.Code, entry.HTTP)
+		}
+		if _, ok := grpcCodes[entry.GRPC]; entry.GRPC != "" && !ok {
+			return fmt.Errorf("code %s: unknown gRPC code %q", entry.Code, entry.GRPC)
+		}
+	}
+	return nil
+}
+
+func (e *Entry) normalizeParams() error {
+	params := make(map[string]bool)
+	keys := make(map[string]bool)
+	for i := range e.Params {
+		param := &e.Params[i]
+		if !token.IsIdentifier(param.Name) || token.IsKeyword(param.Name) {
+			return fmt.Errorf("param %q is not a valid Go identifier", param.Name)
+		}
+		if reservedParams[param.Name] || param.Name == "Code"+e.Name {
+			return fmt.Errorf("param name %s is reserved by the generated code", param.Name)
+		}
+		if params[param.Name] {
+			return fmt.Errorf("param %s declared more than once", param.Name)
+		}
+		params[param.Name] = true
+		if param.Type == "" {
+			param.Type = "any"
+		}
+		if param.Key == "" {
+			param.Key = param.Name
+		}
+		keys[param.Key] = true
+	}
+
+	for _, match := range placeholderPattern.FindAllStringSubmatch(e.Message, -1) {
+		if !params[match[1]] {
+			return fmt.Errorf("message references undeclared param {%s}", match[1])
+		}
+	}
+	for _, key := range e.Context {
+		if !keys[key] {
+			return fmt.Errorf("required context key %q is not provided by any param", key)
+		}
+	}
+	return nil
+}
+
+// inContext reports whether the param is attached to the error context.
+func (e *Entry) inContext(param Param) bool {
+	for _, key := range e.Context {
+		if key == param.Key {
+			return true
+		}
+	}
+	return false
+}
+
+// format returns the fmt format string and arguments for the message.
+func (e *Entry) format() (string, []string) {
+	var args []string
+	format := placeholderPattern.ReplaceAllStringFunc(strings.ReplaceAll(e.Message, "%", "%%"), func(match string) string {
+		args = append(args, match[1:len(match)-1])
+		return "%v"
+	})
+	return format, args
+}
+
+// sentinelMessage returns the message without its placeholders, for the
+// sentinel that has no param values to format. Messages made only of
+// placeholders fall back to the description, then to the code.
+func (e *Entry) sentinelMessage() string {
+	message := strings.Join(strings.Fields(placeholderPattern.ReplaceAllString(e.Message, "")), " ")
+	if message == "" {
+		message = strings.Join(strings.Fields(e.Description), " ")
+	}
+	if message == "" {
+		message = e.Code
+	}
+	return message
+}
+
+// identFromCode turns USER_NOT_FOUND or user.not-found into UserNotFound.
+func identFromCode(code string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(code, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(strings.ToLower(part[1:]))
+	}
+	return b.String()
+}
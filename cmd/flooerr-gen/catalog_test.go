@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadCatalog_YAML(t *testing.T) {
+	catalog, err := loadCatalog("testdata/catalog.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := catalog.normalize(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if catalog.Package != "usererr" {
+		t.Errorf("Expected package 'usererr', got '%s'", catalog.Package)
+	}
+	if len(catalog.Codes) != 3 {
+		t.Fatalf("Expected 3 codes, got %d", len(catalog.Codes))
+	}
+
+	entry := catalog.Codes[0]
+	if entry.Name != "UserNotFound" {
+		t.Errorf("Expected derived name 'UserNotFound', got '%s'", entry.Name)
+	}
+	if entry.HTTP != 404 || entry.GRPC != "NotFound" {
+		t.Errorf("Unexpected mappings: %d %s", entry.HTTP, entry.GRPC)
+	}
+	if entry.Params[0].Type != "int64" || entry.Params[0].Key != "id" {
+		t.Errorf("Unexpected param: %+v", entry.Params[0])
+	}
+}
+
+func TestLoadCatalog_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.json")
+	data := `{"package": "billing", "codes": [{"code": "CARD_DECLINED", "message": "card declined", "http": 402}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalog, err := loadCatalog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if catalog.Codes[0].Code != "CARD_DECLINED" {
+		t.Errorf("Expected code 'CARD_DECLINED', got '%s'", catalog.Codes[0].Code)
+	}
+}
+
+func TestLoadCatalog_NumericCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.yaml")
+	data := "codes:\n  - code: 40401\n    message: not found\n  - code: 0402\n    message: gone\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalog, err := loadCatalog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if catalog.Codes[0].Code != "40401" || catalog.Codes[1].Code != "0402" {
+		t.Errorf("Expected the codes as written, got %q and %q", catalog.Codes[0].Code, catalog.Codes[1].Code)
+	}
+
+	if err := os.WriteFile(path, []byte("codes:\n  - code: 1.5\n    message: x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadCatalog(path); err == nil || !strings.Contains(err.Error(), "code must be a string") {
+		t.Errorf("Expected a clear error for a float code, got %v", err)
+	}
+}
+
+func TestLoadCatalog_UnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.json")
+	data := `{"codes": [{"code": "X", "mesage": "typo"}]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadCatalog(path); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+}
+
+func TestLoadCatalog_UnsupportedExtension(t *testing.T) {
+	if _, err := loadCatalog("errors.toml"); err == nil {
+		t.Error("Expected error for unsupported extension")
+	}
+}
+
+func TestCatalog_Normalize_Errors(t *testing.T) {
+	tests := map[string]Catalog{
+		"no codes":       {Package: "p"},
+		"bad package":    {Package: "1p", Codes: []Entry{{Code: "A", Message: "a"}}},
+		"missing code":   {Codes: []Entry{{Message: "a"}}},
+		"duplicate code": {Codes: []Entry{{Code: "A", Message: "a"}, {Code: "A", Message: "b"}}},
+		"duplicate name": {Codes: []Entry{{Code: "A_B", Message: "a"}, {Code: "a.b", Message: "b"}}},
+		"missing msg":    {Codes: []Entry{{Code: "A"}}},
+		"unexported":     {Codes: []Entry{{Code: "A", Name: "lower", Message: "a"}}},
+		"undeclared":     {Codes: []Entry{{Code: "A", Message: "a {id}"}}},
+		"bad param":      {Codes: []Entry{{Code: "A", Message: "a", Params: []Param{{Name: "func"}}}}},
+		"reserved param": {Codes: []Entry{{Code: "A", Message: "a {msg}", Params: []Param{{Name: "msg"}}}}},
+		"package param":  {Codes: []Entry{{Code: "A", Message: "a", Params: []Param{{Name: "fmt"}}}}},
+		"code param":     {Codes: []Entry{{Code: "A", Message: "a", Params: []Param{{Name: "CodeA"}}}}},
+		"missing key":    {Codes: []Entry{{Code: "A", Message: "a", Context: []string{"id"}}}},
+		"bad http":       {Codes: []Entry{{Code: "A", Message: "a", HTTP: 42}}},
+		"bad grpc":       {Codes: []Entry{{Code: "A", Message: "a", GRPC: "Nope"}}},
+	}
+
+	for name, catalog := range tests {
+		if err := catalog.normalize(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEntry_Format(t *testing.T) {
+	entry := Entry{Message: "{pct}% of {name} used"}
+	format, args := entry.format()
+
+	if format != "%v%% of %v used" {
+		t.Errorf("Unexpected format '%s'", format)
+	}
+	if strings.Join(args, ",") != "pct,name" {
+		t.Errorf("Unexpected args %v", args)
+	}
+}
+
+func TestEntry_SentinelMessage(t *testing.T) {
+	tests := map[string]Entry{
+		"user not found":       {Code: "USER_NOT_FOUND", Message: "user {id} not found"},
+		"too many requests":    {Code: "RATE_LIMITED", Message: "too many requests"},
+		"Request was rejected": {Code: "REJECTED", Message: "{reason}", Description: "Request was\nrejected"},
+		"UNKNOWN":              {Code: "UNKNOWN", Message: "{reason}"},
+	}
+
+	for expected, entry := range tests {
+		if actual := entry.sentinelMessage(); actual != expected {
+			t.Errorf("%s: expected '%s', got '%s'", entry.Code, expected, actual)
+		}
+	}
+}
+
+func TestIdentFromCode(t *testing.T) {
+	tests := map[string]string{
+		"USER_NOT_FOUND":  "UserNotFound",
+		"payment.card-v2": "PaymentCardV2",
+		"Simple":          "Simple",
+	}
+
+	for code, expected := range tests {
+		if actual := identFromCode(code); actual != expected {
+			t.Errorf("identFromCode(%q): expected '%s', got '%s'", code, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const flooerrImport = "core-common-go/flooerr"
+
+var goTemplate = template.Must(template.New("go").Funcs(template.FuncMap{
+	"quote":   strconv.Quote,
+	"oneline": oneline,
+}).Parse(`// Code generated by flooerr-gen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .NeedsFmt}}
+	"fmt"
+{{end}}
+	"{{.Import}}"
+)
+
+// Error codes declared in {{.Source}}.
+const (
+{{- range .Codes}}
+	// Code{{.Name}} identifies {{.Code}} errors.{{with oneline .Description}} {{.}}{{end}}
+	Code{{.Name}} flooerr.ErrCode = {{quote .Code}}
+{{- end}}
+)
+
+// Sentinels for use with errors.Is. Errors built by the constructors below
+// match the sentinel of their code, even when wrapped.
+var (
+{{- range .Codes}}
+	{{.Name}} = flooerr.Sentinel(Code{{.Name}}, {{quote .SentinelMessage}})
+{{- end}}
+)
+{{range .Codes}}
+// Err{{.Name}} builds an error with code {{.Code}}.{{with oneline .Description}} {{.}}{{end}}
+func Err{{.Name}}({{.Signature}}) error {
+{{- if .Args}}
+	msg := fmt.Sprintf({{quote .Format}}, {{.Args}})
+{{- else}}
+	msg := {{quote .Format}}
+{{- end}}
+	return flooerr.Code(Code{{.Name}}).
+		WithMessage(msg).
+{{- range .ContextParams}}
+		WithContext({{quote .Key}}, {{.Name}}).
+{{- end}}
+		BuildSkip(0, nil, msg)
+}
+{{end}}
+// HTTPStatus maps codes to HTTP status codes.
+var HTTPStatus = map[flooerr.ErrCode]int{
+{{- range .Codes}}{{if .HTTP}}
+	Code{{.Name}}: {{.HTTP}},
+{{- end}}{{end}}
+}
+
+// GRPCCode maps codes to google.golang.org/grpc/codes values.
+var GRPCCode = map[flooerr.ErrCode]uint32{
+{{- range .Codes}}{{if .GRPC}}
+	Code{{.Name}}: {{.GRPCValue}}, // {{.GRPC}}
+{{- end}}{{end}}
+}
+`))
+
+var markdownTemplate = template.Must(template.New("md").Funcs(template.FuncMap{
+	"cell": markdownCell,
+}).Parse(`# {{.Package}} error reference
+
+Generated by flooerr-gen from ` + "`{{.Source}}`" + `. Do not edit.
+
+| Code | HTTP | gRPC | Message |
+| ---- | ---- | ---- | ------- |
+{{- range .Codes}}
+| ` + "`{{.Code}}`" + ` | {{if .HTTP}}{{.HTTP}}{{end}} | {{.GRPC}} | {{cell .Message}} |
+{{- end}}
+{{range .Codes}}
+## {{.Code}}
+
+Constructor: ` + "`Err{{.Name}}({{.Signature}})`" + `
+{{- if .Description}}
+
+{{.Description}}
+{{- end}}
+{{- if .Params}}
+
+| Param | Type | Context key |
+| ----- | ---- | ----------- |
+{{- range .Params}}
+| ` + "`{{.Name}}`" + ` | ` + "`{{.Type}}`" + ` | {{if .InContext}}` + "`{{.Key}}`" + `{{end}} |
+{{- end}}
+{{- end}}
+{{end}}`))
+
+type templateData struct {
+	Source   string
+	Package  string
+	Import   string
+	NeedsFmt bool
+	Codes    []templateCode
+}
+
+type templateCode struct {
+	Entry
+	Signature       string
+	SentinelMessage string
+	Format          string
+	Args            string
+	Params          []templateParam
+	ContextParams   []templateParam
+	GRPCValue       uint32
+}
+
+type templateParam struct {
+	Param
+	InContext bool
+}
+
+func newTemplateData(catalog *Catalog, source string) templateData {
+	data := templateData{
+		Source:  source,
+		Package: catalog.Package,
+		Import:  flooerrImport,
+	}
+	for _, entry := range catalog.Codes {
+		format, args := entry.format()
+		code := templateCode{
+			Entry:           entry,
+			SentinelMessage: entry.sentinelMessage(),
+			Format:          format,
+			Args:            strings.Join(args, ", "),
+			GRPCValue:       grpcCodes[entry.GRPC],
+		}
+		if len(args) > 0 {
+			data.NeedsFmt = true
+		}
+
+		var signature []string
+		for _, param := range entry.Params {
+			p := templateParam{Param: param, InContext: entry.inContext(param)}
+			code.Params = append(code.Params, p)
+			if p.InContext {
+				code.ContextParams = append(code.ContextParams, p)
+			}
+			signature = append(signature, param.Name+" "+param.Type)
+		}
+		code.Signature = strings.Join(signature, ", ")
+		data.Codes = append(data.Codes, code)
+	}
+	return data
+}
+
+// generateGo renders the Go source for the catalog.
+func generateGo(catalog *Catalog, source string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := goTemplate.Execute(&buf, newTemplateData(catalog, source)); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+// generateMarkdown renders the reference page for the catalog.
+func generateMarkdown(catalog *Catalog, source string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := markdownTemplate.Execute(&buf, newTemplateData(catalog, source)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// oneline collapses whitespace so text fits in a single comment line.
+func oneline(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func markdownCell(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "|", `\|`), "\n", " ")
+}
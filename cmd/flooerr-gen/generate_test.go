@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func loadTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	catalog, err := loadCatalog("testdata/catalog.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := catalog.normalize(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return catalog
+}
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading golden file: %v", err)
+	}
+	if string(expected) != string(actual) {
+		t.Errorf("Output does not match %s (run with -update to rewrite):\n%s", path, actual)
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	src, err := generateGo(loadTestCatalog(t), "catalog.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "catalog_gen.go", src, 0); err != nil {
+		t.Fatalf("Generated code does not parse: %v", err)
+	}
+	assertGolden(t, "catalog_gen.go.golden", src)
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	md, err := generateMarkdown(loadTestCatalog(t), "catalog.yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	assertGolden(t, "catalog.md.golden", md)
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "errors_gen.go")
+	doc := filepath.Join(dir, "ERRORS.md")
+
+	err := run([]string{"-catalog", "testdata/catalog.yaml", "-out", out, "-doc", doc, "-pkg", "override"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), out, nil, parser.PackageClauseOnly)
+	if err != nil {
+		t.Fatalf("Generated code does not parse: %v", err)
+	}
+	if file.Name.Name != "override" {
+		t.Errorf("Expected package 'override', got '%s'", file.Name.Name)
+	}
+	if _, err := os.Stat(doc); err != nil {
+		t.Errorf("Expected markdown page to be written: %v", err)
+	}
+}
+
+func TestRun_MissingCatalog(t *testing.T) {
+	if err := run([]string{}); err == nil {
+		t.Error("Expected error when -catalog is missing")
+	}
+}
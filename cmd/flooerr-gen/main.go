@@ -0,0 +1,80 @@
+// Command flooerr-gen generates Go code and a markdown reference page from an
+// error catalog. It is meant to be run through go:generate:
+//
+//	//go:generate go run core-common-go/cmd/flooerr-gen -catalog errors.yaml -out errors_gen.go -doc ERRORS.md
+//
+// For every code in the catalog it emits a typed flooerr.ErrCode constant, a
+// flooerr.Sentinel for errors.Is, a constructor built on the ErrProps builder
+// and entries in the HTTPStatus and GRPCCode mapping tables.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "flooerr-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("flooerr-gen", flag.ContinueOnError)
+	catalogPath := flags.String("catalog", "", "path to the .yaml, .yml or .json error catalog (required)")
+	out := flags.String("out", "", "output Go file (default: <catalog>_gen.go)")
+	doc := flags.String("doc", "", "optional output markdown reference page")
+	pkg := flags.String("pkg", "", "package name (default: catalog package, then $GOPACKAGE)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *catalogPath == "" {
+		flags.Usage()
+		return fmt.Errorf("-catalog is required")
+	}
+
+	catalog, err := loadCatalog(*catalogPath)
+	if err != nil {
+		return err
+	}
+	switch {
+	case *pkg != "":
+		catalog.Package = *pkg
+	case catalog.Package == "":
+		catalog.Package = os.Getenv("GOPACKAGE")
+	}
+	if catalog.Package == "" {
+		return fmt.Errorf("package name not set: use -pkg, the catalog package field or go:generate")
+	}
+	if err := catalog.normalize(); err != nil {
+		return fmt.Errorf("%s: %w", *catalogPath, err)
+	}
+
+	source := filepath.Base(*catalogPath)
+	if *out == "" {
+		ext := filepath.Ext(*catalogPath)
+		*out = (*catalogPath)[:len(*catalogPath)-len(ext)] + "_gen.go"
+	}
+
+	src, err := generateGo(catalog, source)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		return err
+	}
+
+	if *doc != "" {
+		md, err := generateMarkdown(catalog, source)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*doc, md, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML_Mapping(t *testing.T) {
+	doc, err := parseYAML([]byte(`
+# leading comment
+name: users   # trailing comment
+count: 3
+padded: 0401
+ratio: 0.5
+enabled: true
+missing: ~
+quoted: "a: b # not a comment"
+single: 'it''s'
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]any{
+		"name":    "users",
+		"count":   int64(3),
+		"padded":  "0401",
+		"ratio":   0.5,
+		"enabled": true,
+		"missing": nil,
+		"quoted":  "a: b # not a comment",
+		"single":  "it's",
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("Expected %v, got %v", expected, doc)
+	}
+}
+
+func TestParseYAML_Sequences(t *testing.T) {
+	doc, err := parseYAML([]byte(`
+flow: [a, "b, c", 3]
+empty: []
+block:
+  - one
+  - two
+same_indent:
+- x
+items:
+  - name: first
+    tags: [t1]
+  - name: second
+    nested:
+      - deep
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]any{
+		"flow":        []any{"a", "b, c", int64(3)},
+		"empty":       []any{},
+		"block":       []any{"one", "two"},
+		"same_indent": []any{"x"},
+		"items": []any{
+			map[string]any{"name": "first", "tags": []any{"t1"}},
+			map[string]any{"name": "second", "nested": []any{"deep"}},
+		},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("Expected %v, got %v", expected, doc)
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	tests := map[string]string{
+		"duplicate key":     "a: 1\na: 2\n",
+		"bad indentation":   "a: 1\n   b: 2\n",
+		"missing colon":     "a: 1\njust text\n",
+		"unterminated flow": "a: [1, 2\n",
+		"bad quote":         "a: \"open\n",
+	}
+
+	for name, input := range tests {
+		if _, err := parseYAML([]byte(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseYAML_Empty(t *testing.T) {
+	doc, err := parseYAML([]byte("# only a comment\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if doc != nil {
+		t.Errorf("Expected nil document, got %v", doc)
+	}
+}
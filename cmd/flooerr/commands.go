@@ -0,0 +1,292 @@
+package main
+
+import (
+	"core-common-go/flooerr"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func runShow(args []string, stdin io.Reader, stdout io.Writer) (int, error) {
+	flags := flag.NewFlagSet("flooerr show", flag.ContinueOnError)
+	color := flags.String("color", "auto", "colorize output: auto, always or never")
+	source := flags.Bool("source", false, "show source lines around in-module frames, read from the local files the frames name")
+	if err := flags.Parse(args); err != nil {
+		return 0, err
+	}
+
+	// Logs usually come from another machine or build, so the local files
+	// named in their frames are only read on request.
+	opts := flooerr.PrettyOptions{NoSource: !*source}
+	switch *color {
+	case "auto":
+		file, ok := stdout.(*os.File)
+		if !ok {
+			opts.Color = flooerr.ColorNever
+		}
+		opts.Output = file
+	case "always":
+		opts.Color = flooerr.ColorAlways
+	case "never":
+		opts.Color = flooerr.ColorNever
+	default:
+		return 0, fmt.Errorf("invalid -color %q", *color)
+	}
+
+	first := true
+	err := readInputs(flags.Args(), stdin, func(e entry) {
+		if !first {
+			fmt.Fprintln(stdout)
+		}
+		first = false
+		fmt.Fprintf(stdout, "==> %s%s\n", e.Source, formatTime(e.Time, "  "))
+		fmt.Fprint(stdout, flooerr.Pretty(e.Err, opts))
+	})
+	return 0, err
+}
+
+// sdcFlags collects repeated -sdc key=value flags.
+type sdcFlags map[string]string
+
+func (f sdcFlags) String() string {
+	return fmt.Sprint(map[string]string(f))
+}
+
+func (f sdcFlags) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	f[key] = val
+	return nil
+}
+
+// jsonEntry is the -json form of a grep match.
+type jsonEntry struct {
+	Source string          `json:"source"`
+	Time   *time.Time      `json:"time,omitempty"`
+	Error  json.RawMessage `json:"error"`
+}
+
+func runGrep(args []string, stdin io.Reader, stdout io.Writer) (int, error) {
+	flags := flag.NewFlagSet("flooerr grep", flag.ContinueOnError)
+	code := flags.String("code", "", "match errors with this code on any layer")
+	sdc := sdcFlags{}
+	flags.Var(sdc, "sdc", "match errors whose merged SDC has key=value (repeatable)")
+	message := flags.String("message", "", "match errors whose text contains this")
+	asJSON := flags.Bool("json", false, "print matches as JSON lines")
+	if err := flags.Parse(args); err != nil {
+		return 0, err
+	}
+
+	table := newTable(stdout, "TIME", "SOURCE", "ID", "CODE", "ERROR")
+	encoder := json.NewEncoder(stdout)
+	matches := 0
+	err := readInputs(flags.Args(), stdin, func(e entry) {
+		if !matchEntry(e, *code, sdc, *message) {
+			return
+		}
+		matches++
+		if *asJSON {
+			out := jsonEntry{Source: e.Source, Error: e.Raw}
+			if !e.Time.IsZero() {
+				out.Time = &e.Time
+			}
+			encoder.Encode(out)
+			return
+		}
+		table.row(formatTime(e.Time, ""), e.Source, flooerr.GetID(e.Err), orDash(flooerr.EffectiveCode(e.Err).String()), e.Err.Error())
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !*asJSON && matches > 0 {
+		table.flush()
+	}
+	if matches == 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func matchEntry(e entry, code string, sdc map[string]string, message string) bool {
+	if code != "" && !hasCodeInChain(e.Err, code) {
+		return false
+	}
+	if len(sdc) > 0 {
+		merged := flooerr.MergedSDC(e.Err)
+		for key, value := range sdc {
+			if merged[key] != value {
+				return false
+			}
+		}
+	}
+	return message == "" || strings.Contains(e.Err.Error(), message)
+}
+
+func hasCodeInChain(err error, code string) bool {
+	for flooErr := range flooerr.FlooErrs(err) {
+		if flooErr.Code().String() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// group is a set of errors sharing a code or fingerprint.
+type group struct {
+	Key       string    `json:"key"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Example   string    `json:"example"`
+}
+
+func runGroup(args []string, stdin io.Reader, stdout io.Writer, top bool) (int, error) {
+	name := "flooerr group"
+	if top {
+		name = "flooerr top"
+	}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	by := flags.String("by", "code", "group by code or fingerprint")
+	limit := 0
+	if top {
+		flags.IntVar(&limit, "n", 10, "number of groups to list")
+	}
+	asJSON := flags.Bool("json", false, "print groups as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 0, err
+	}
+
+	var keyOf func(error) string
+	switch *by {
+	case "code":
+		keyOf = func(err error) string { return orDash(flooerr.EffectiveCode(err).String()) }
+	case "fingerprint":
+		keyOf = fingerprint
+	default:
+		return 0, fmt.Errorf("invalid -by %q", *by)
+	}
+
+	groups := make(map[string]*group)
+	err := readInputs(flags.Args(), stdin, func(e entry) {
+		key := keyOf(e.Err)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{Key: key, FirstSeen: e.Time, LastSeen: e.Time, Example: e.Err.Error()}
+			groups[key] = g
+		}
+		g.Count++
+		if !e.Time.IsZero() && (g.FirstSeen.IsZero() || e.Time.Before(g.FirstSeen)) {
+			g.FirstSeen = e.Time
+		}
+		if e.Time.After(g.LastSeen) {
+			g.LastSeen = e.Time
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sorted := make([]group, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, *g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if top && sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return 0, encoder.Encode(sorted)
+	}
+
+	table := newTable(stdout, "COUNT", "FIRST SEEN", "LAST SEEN", strings.ToUpper(*by), "EXAMPLE")
+	for _, g := range sorted {
+		table.row(fmt.Sprint(g.Count), formatTime(g.FirstSeen, ""), formatTime(g.LastSeen, ""), g.Key, g.Example)
+	}
+	table.flush()
+	return 0, nil
+}
+
+var digits = regexp.MustCompile(`\d+`)
+
+// fingerprint identifies errors with the same cause: the codes along the
+// chain and the function where the innermost stack starts, or the root
+// message with numbers masked when no stack was logged.
+func fingerprint(err error) string {
+	var codes []string
+	origin := ""
+	for flooErr := range flooerr.FlooErrs(err) {
+		if code := flooErr.Code(); code != "" {
+			codes = append(codes, code.String())
+		}
+		if stack := flooErr.StackTrace(); len(stack) > 0 {
+			origin = stack[0].Function
+		}
+	}
+	if origin == "" {
+		origin = digits.ReplaceAllString(flooerr.GetRootCause(err).Error(), "#")
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(codes, "|") + "\n" + origin))
+	return hex.EncodeToString(sum[:6])
+}
+
+func formatTime(t time.Time, prefix string) string {
+	if t.IsZero() {
+		if prefix != "" {
+			return ""
+		}
+		return "-"
+	}
+	return prefix + t.UTC().Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// table writes tab-aligned columns.
+type table struct {
+	w *tabwriter.Writer
+}
+
+func newTable(out io.Writer, headers ...string) *table {
+	t := &table{w: tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)}
+	t.row(headers...)
+	return t
+}
+
+// cellEscaper keeps multi-line error text on its row.
+var cellEscaper = strings.NewReplacer("\t", " ", "\n", `\n`, "\r", `\r`)
+
+func (t *table) row(columns ...string) {
+	for i, column := range columns {
+		columns[i] = cellEscaper.Replace(column)
+	}
+	fmt.Fprintln(t.w, strings.Join(columns, "\t"))
+}
+
+func (t *table) flush() {
+	t.w.Flush()
+}
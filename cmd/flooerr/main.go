@@ -0,0 +1,64 @@
+// Command flooerr inspects FlooErrs in JSON-lines logs. It reads files, or
+// stdin when no file is given, and finds the error object of every record:
+// a JSON object in the shape written by flooerr's MarshalJSON, either the
+// record itself or nested under a key such as "error". Records whose error
+// is a plain string are kept with just that text; other lines are skipped.
+//
+// Usage:
+//
+//	flooerr show  [-color auto|always|never] [-source] [file...]
+//	flooerr grep  [-code CODE] [-sdc key=value]... [-message text] [-json] [file...]
+//	flooerr group [-by code|fingerprint] [-json] [file...]
+//	flooerr top   [-by code|fingerprint] [-n 10] [-json] [file...]
+//
+// show pretty-prints every error; with -source it adds the lines around
+// in-module frames, read from the local files the frames name. grep prints the errors with CODE on any
+// layer, the given merged SDC values and message text. group counts errors
+// by code or fingerprint with the first and last time they were seen, and
+// top lists the most frequent groups. grep exits with status 1 when nothing
+// matches.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: flooerr <command> [flags] [file...]
+
+commands:
+  show   pretty-print every error
+  grep   print errors matching -code, -sdc and -message
+  group  count errors by code or fingerprint
+  top    list the most frequent error groups`
+
+func main() {
+	code, err := run(os.Args[1:], os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "flooerr:", err)
+		os.Exit(2)
+	}
+	os.Exit(code)
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) (int, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("missing command\n%s", usage)
+	}
+
+	switch args[0] {
+	case "show":
+		return runShow(args[1:], stdin, stdout)
+	case "grep":
+		return runGrep(args[1:], stdin, stdout)
+	case "group":
+		return runGroup(args[1:], stdin, stdout, false)
+	case "top":
+		return runGroup(args[1:], stdin, stdout, true)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprintln(stdout, usage)
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"core-common-go/flooerr"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading golden file: %v", err)
+	}
+	if string(expected) != string(actual) {
+		t.Errorf("Output does not match %s (run with -update to rewrite):\n%s", path, actual)
+	}
+}
+
+func runCommand(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	var out bytes.Buffer
+	code, err := run(args, strings.NewReader(""), &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return code, out.String()
+}
+
+func TestRun_Golden(t *testing.T) {
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{"show.golden", []string{"show", "-color", "never", "testdata/app.log"}},
+		{"grep.golden", []string{"grep", "-code", "NOT_FOUND", "testdata/app.log"}},
+		{"grep_json.golden", []string{"grep", "-sdc", "service=billing", "-json", "testdata/app.log"}},
+		{"group.golden", []string{"group", "testdata/app.log"}},
+		{"group_fingerprint.golden", []string{"group", "-by", "fingerprint", "testdata/app.log"}},
+		{"top_json.golden", []string{"top", "-n", "2", "-json", "testdata/app.log"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			code, out := runCommand(t, tt.args...)
+			if code != 0 {
+				t.Errorf("Expected exit code 0, got %d", code)
+			}
+			assertGolden(t, tt.golden, []byte(out))
+		})
+	}
+}
+
+func TestGrep_Filters(t *testing.T) {
+	if _, out := runCommand(t, "grep", "-code", "USER_ERR", "-sdc", "request_id=r2", "testdata/app.log"); strings.Count(out, "\n") != 2 || !strings.Contains(out, "testdata/app.log:4") {
+		t.Errorf("Expected one match on line 4:\n%s", out)
+	}
+
+	if _, out := runCommand(t, "grep", "-message", "connection refused", "testdata/app.log"); !strings.Contains(out, "testdata/app.log:5") {
+		t.Errorf("Expected the plain string error to match:\n%s", out)
+	}
+
+	if code, out := runCommand(t, "grep", "-code", "MISSING", "testdata/app.log"); code != 1 || out != "" {
+		t.Errorf("Expected exit code 1 and no output, got %d %q", code, out)
+	}
+}
+
+func TestShow_Source(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {\n\tpanic(\"marker line\")\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "app.log")
+	record := `{"error":{"error":"boom","code":"BOOM","stack":[{"function":"main.main","file":` + strconv.Quote(file) + `,"line":4}]}}` + "\n"
+	if err := os.WriteFile(log, []byte(record), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, out := runCommand(t, "show", "-color", "never", log); strings.Contains(out, "marker line") {
+		t.Errorf("Expected no source lines by default:\n%s", out)
+	}
+	if _, out := runCommand(t, "show", "-color", "never", "-source", log); !strings.Contains(out, "marker line") {
+		t.Errorf("Expected source lines with -source:\n%s", out)
+	}
+}
+
+func TestGroup_MultiLineErrors(t *testing.T) {
+	input := `{"error":{"error":"query failed:\nsyntax error\r\nnear FROM","code":"SQL"}}` + "\n"
+
+	var out bytes.Buffer
+	if _, err := run([]string{"group", "-by", "fingerprint"}, strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `query failed:\nsyntax error\r\nnear FROM`) {
+		t.Errorf("Expected the message escaped on one row:\n%s", out.String())
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	input := `{"error":{"error":"boom","code":"BOOM"}}` + "\n"
+
+	for _, args := range [][]string{{"group"}, {"group", "-"}} {
+		var out bytes.Buffer
+		if _, err := run(args, strings.NewReader(input), &out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(out.String(), "BOOM") {
+			t.Errorf("Expected stdin to be read:\n%s", out.String())
+		}
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"unknown"},
+		{"group", "-by", "color"},
+		{"show", "-color", "pink"},
+		{"grep", "-sdc", "novalue"},
+		{"show", "testdata/missing.log"},
+	} {
+		var out bytes.Buffer
+		if _, err := run(args, strings.NewReader(""), &out); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestFindError(t *testing.T) {
+	e, ok := parseLine([]byte(`{"msg":"x","data":{"error":{"error":"nested","code":"DEEP"}}}`))
+	if !ok || flooerr.EffectiveCode(e.Err) != "DEEP" {
+		t.Errorf("Expected the nested error, got %v %v", e.Err, ok)
+	}
+
+	for _, line := range []string{`not json`, `{"msg":"ok"}`, `{"error":""}`, `[1,2]`} {
+		if _, ok := parseLine([]byte(line)); ok {
+			t.Errorf("Expected no error in %s", line)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"core-common-go/flooerr"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// maxLineSize bounds a single log line.
+const maxLineSize = 16 << 20
+
+// entry is an error found in a log record.
+type entry struct {
+	Source string
+	Time   time.Time
+	Raw    json.RawMessage
+	Err    error
+}
+
+// errorKeys are checked first when looking for a nested error object.
+var errorKeys = []string{"error", "err", "exception"}
+
+// timeKeys hold the record timestamp.
+var timeKeys = []string{"time", "ts", "timestamp", "@timestamp"}
+
+// errorFields mark an object as a FlooErr JSON encoding.
+var errorFields = []string{"code", "id", "op", "message", "context", "sdc", "stack", "cause"}
+
+// readInputs calls visit for every error in files, or in stdin when files is
+// empty or "-".
+func readInputs(files []string, stdin io.Reader, visit func(entry)) error {
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		if name == "-" {
+			if err := readEntries("stdin", stdin, visit); err != nil {
+				return err
+			}
+			continue
+		}
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = readEntries(name, file, visit)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readEntries(name string, r io.Reader, visit func(entry)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if e, ok := parseLine(scanner.Bytes()); ok {
+			e.Source = fmt.Sprintf("%s:%d", name, line)
+			visit(e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// parseLine extracts the error of one JSON log record.
+func parseLine(line []byte) (entry, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	var record map[string]any
+	if decoder.Decode(&record) != nil {
+		return entry{}, false
+	}
+
+	object, ok := findError(record, 0)
+	if !ok {
+		return entry{}, false
+	}
+	raw, err := json.Marshal(object)
+	if err != nil {
+		return entry{}, false
+	}
+	decoded, err := flooerr.DecodeJSON(raw)
+	if err != nil {
+		return entry{}, false
+	}
+
+	e := entry{Raw: raw, Err: decoded, Time: recordTime(record)}
+	if e.Time.IsZero() {
+		e.Time = flooerr.Parse(decoded).Time
+	}
+	return e, true
+}
+
+// findError returns the error object of a record: the record itself when it
+// is a FlooErr encoding, otherwise the first one found under errorKeys and
+// then under the remaining keys. A plain string under errorKeys counts as an
+// error with only that text.
+func findError(object map[string]any, depth int) (map[string]any, bool) {
+	if looksLikeError(object) {
+		return object, true
+	}
+	if depth >= 4 {
+		return nil, false
+	}
+
+	for _, key := range errorKeys {
+		switch value := object[key].(type) {
+		case map[string]any:
+			if found, ok := findError(value, depth+1); ok {
+				return found, true
+			}
+		case string:
+			if value != "" {
+				return map[string]any{"error": value}, true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if nested, ok := object[key].(map[string]any); ok {
+			if found, ok := findError(nested, depth+1); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func looksLikeError(object map[string]any) bool {
+	if _, ok := object["error"].(string); !ok {
+		return false
+	}
+	for _, field := range errorFields {
+		if _, ok := object[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// recordTime returns the timestamp of a record, accepting RFC 3339 strings
+// and Unix seconds.
+func recordTime(record map[string]any) time.Time {
+	for _, key := range timeKeys {
+		switch value := record[key].(type) {
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				return t
+			}
+		case json.Number:
+			if seconds, err := strconv.ParseFloat(value.String(), 64); err == nil {
+				return time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+			}
+		}
+	}
+	return time.Time{}
+}
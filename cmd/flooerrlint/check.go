@@ -0,0 +1,746 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	flooerrPath  = "core-common-go/flooerr"
+	internalPath = "core-common-go/flooerr/internal"
+)
+
+// Check names, as printed in diagnostics and accepted by -checks.
+const (
+	checkAssert   = "assert"
+	checkNilWrap  = "nilwrap"
+	checkUnbuilt  = "unbuilt"
+	checkCode     = "code"
+	checkWrapVerb = "wrapverb"
+)
+
+var allChecks = []string{checkAssert, checkNilWrap, checkUnbuilt, checkCode, checkWrapVerb}
+
+// terminalMethods are the ErrProps methods that produce an error.
+var terminalMethods = map[string]bool{
+	"Build":     true,
+	"BuildSkip": true,
+	"Error":     true,
+	"Errorf":    true,
+	"Wrap":      true,
+	"Wrapf":     true,
+	"WrapIf":    true,
+}
+
+// builderFuncs are the flooerr functions that start a builder chain.
+var builderFuncs = map[string]bool{
+	"Message":    true,
+	"Code":       true,
+	"StackTrace": true,
+	"Context":    true,
+	"SDC":        true,
+	"Op":         true,
+}
+
+// chainFuncs are the flooerr functions that take a builder as their first
+// argument and return it, like flooerr.With.
+var chainFuncs = map[string]bool{
+	"With": true,
+}
+
+// errorType is the built-in error interface.
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// TextEdit replaces the source between Pos and End with NewText.
+type TextEdit struct {
+	Pos     token.Pos
+	End     token.Pos
+	NewText string
+}
+
+// Diagnostic is a single finding.
+type Diagnostic struct {
+	Pos     token.Pos
+	Check   string
+	Message string
+	Fix     string
+	Edits   []TextEdit
+}
+
+// codeConst is a known code constant.
+type codeConst struct {
+	Path string // import path of the declaring package
+	Pkg  string // name of the declaring package
+	Name string
+}
+
+func (k codeConst) String() string {
+	return k.Pkg + "." + k.Name
+}
+
+type checker struct {
+	pkg     *checkedPackage
+	enabled map[string]bool
+	codes   map[string]codeConst // keyed by code value
+	parents map[ast.Node]ast.Node
+	file    *ast.File
+	sources map[string][]byte
+	ignored map[string]map[int][]string // file name -> line -> checks
+	diags   []Diagnostic
+}
+
+// ignoreDirective suppresses the findings on its own line and the next one.
+// It may be followed by the checks to suppress; without them it suppresses
+// every check:
+//
+//	//flooerrlint:ignore assert
+const ignoreDirective = "//flooerrlint:ignore"
+
+// checkPkg runs the enabled checks over pkg and returns the findings sorted
+// by position. The flooerr package itself, which deliberately works on
+// single chain elements, is not checked.
+func checkPkg(pkg *checkedPackage, enabled map[string]bool) []Diagnostic {
+	if pkg.Path == flooerrPath || pkg.Path == internalPath {
+		return nil
+	}
+	c := &checker{
+		pkg:     pkg,
+		enabled: enabled,
+		codes:   knownCodes(pkg.Types),
+		parents: make(map[ast.Node]ast.Node),
+		sources: make(map[string][]byte),
+		ignored: make(map[string]map[int][]string),
+	}
+
+	for _, file := range pkg.Files {
+		c.file = file
+		c.collectIgnores(file)
+		var stack []ast.Node
+		ast.Inspect(file, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return false
+			}
+			if len(stack) > 0 {
+				c.parents[n] = stack[len(stack)-1]
+			}
+			stack = append(stack, n)
+			return true
+		})
+		ast.Inspect(file, c.visit)
+	}
+	if enabled[checkUnbuilt] {
+		c.checkUnbuiltVars()
+	}
+
+	sort.SliceStable(c.diags, func(i, j int) bool { return c.diags[i].Pos < c.diags[j].Pos })
+	return c.diags
+}
+
+func (c *checker) report(d Diagnostic) {
+	if c.enabled[d.Check] && !c.isIgnored(d) {
+		c.diags = append(c.diags, d)
+	}
+}
+
+// collectIgnores records the ignore directives of file.
+func (c *checker) collectIgnores(file *ast.File) {
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			rest, ok := strings.CutPrefix(comment.Text, ignoreDirective)
+			if !ok || rest != "" && rest[0] != ' ' {
+				continue
+			}
+			position := c.pkg.Fset.Position(comment.Slash)
+			lines := c.ignored[position.Filename]
+			if lines == nil {
+				lines = make(map[int][]string)
+				c.ignored[position.Filename] = lines
+			}
+			checks := strings.FieldsFunc(rest, func(r rune) bool { return r == ' ' || r == ',' })
+			if len(checks) == 0 {
+				checks = allChecks
+			}
+			lines[position.Line] = append(lines[position.Line], checks...)
+		}
+	}
+}
+
+// isIgnored reports whether a directive on the line of d, or the line
+// above, suppresses it.
+func (c *checker) isIgnored(d Diagnostic) bool {
+	position := c.pkg.Fset.Position(d.Pos)
+	lines := c.ignored[position.Filename]
+	for _, line := range []int{position.Line, position.Line - 1} {
+		for _, check := range lines[line] {
+			if check == d.Check {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *checker) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.TypeAssertExpr:
+		c.checkTypeAssert(n)
+	case *ast.TypeSwitchStmt:
+		c.checkTypeSwitch(n)
+	case *ast.CallExpr:
+		c.checkNilWrap(n)
+		c.checkWrapVerb(n)
+		c.checkCodeArgs(n)
+	case *ast.BinaryExpr:
+		c.checkCodeCompare(n)
+	case *ast.ExprStmt:
+		c.checkUnbuiltStmt(n)
+	}
+	return true
+}
+
+// checkTypeAssert flags x.(flooerr.FlooErr), which misses wrapped errors.
+func (c *checker) checkTypeAssert(n *ast.TypeAssertExpr) {
+	if n.Type == nil || !isFlooErrType(c.pkg.Info.TypeOf(n.Type)) {
+		return
+	}
+
+	d := Diagnostic{
+		Pos:     n.Pos(),
+		Check:   checkAssert,
+		Message: "type assertion to flooerr.FlooErr misses wrapped errors",
+		Fix:     "use flooerr.AsFlooErr or errors.As",
+	}
+	// AsFlooErr takes an error, so operands such as any keep the assertion.
+	if assign, ok := c.parents[n].(*ast.AssignStmt); ok && len(assign.Lhs) == 2 && implementsError(c.pkg.Info.TypeOf(n.X)) {
+		d.Edits = []TextEdit{{
+			Pos:     n.Pos(),
+			End:     n.End(),
+			NewText: c.qualify("AsFlooErr") + "(" + c.source(n.X) + ")",
+		}}
+	}
+	c.report(d)
+}
+
+// implementsError reports whether values of t can be passed as an error.
+func implementsError(t types.Type) bool {
+	return t != nil && types.Implements(t, errorType)
+}
+
+// checkTypeSwitch flags `case flooerr.FlooErr:` in a type switch.
+func (c *checker) checkTypeSwitch(n *ast.TypeSwitchStmt) {
+	for _, stmt := range n.Body.List {
+		clause := stmt.(*ast.CaseClause)
+		for _, expr := range clause.List {
+			if isFlooErrType(c.pkg.Info.TypeOf(expr)) {
+				c.report(Diagnostic{
+					Pos:     expr.Pos(),
+					Check:   checkAssert,
+					Message: "type switch case flooerr.FlooErr misses wrapped errors",
+					Fix:     "use flooerr.AsFlooErr or errors.As before switching",
+				})
+			}
+		}
+	}
+}
+
+// checkNilWrap flags wrapping a literal nil cause, which builds a new error
+// instead of propagating nil.
+func (c *checker) checkNilWrap(n *ast.CallExpr) {
+	name, isMethod, ok := c.flooerrCallee(n)
+	if !ok || len(n.Args) == 0 || !c.isNil(n.Args[0]) {
+		return
+	}
+
+	var replacement string
+	switch {
+	case !isMethod && name == "Wrap":
+		replacement = "Error"
+	case !isMethod && name == "WrapF":
+		replacement = "ErrorF"
+	case isMethod && (name == "Wrap" || name == "Wrapf"):
+		replacement = "Build"
+		if name == "Wrapf" {
+			replacement = "Errorf"
+		}
+	default:
+		return
+	}
+
+	d := Diagnostic{
+		Pos:     n.Pos(),
+		Check:   checkNilWrap,
+		Message: fmt.Sprintf("%s called with a nil cause creates a new error", name),
+		Fix:     fmt.Sprintf("use %s to create an error without a cause", replacement),
+	}
+	if !isMethod {
+		d.Edits = []TextEdit{{
+			Pos:     n.Pos(),
+			End:     n.End(),
+			NewText: c.qualify(replacement) + "(" + c.sourceList(n.Args[1:]) + ")",
+		}}
+	} else if replacement == "Errorf" {
+		sel := n.Fun.(*ast.SelectorExpr)
+		d.Edits = []TextEdit{{
+			Pos:     sel.Sel.Pos(),
+			End:     n.End(),
+			NewText: replacement + "(" + c.sourceList(n.Args[1:]) + ")",
+		}}
+	} else {
+		sel := n.Fun.(*ast.SelectorExpr)
+		d.Edits = []TextEdit{{Pos: sel.Sel.Pos(), End: sel.Sel.End(), NewText: replacement}}
+	}
+	c.report(d)
+}
+
+// checkWrapVerb flags %w in format strings that are formatted with
+// fmt.Sprintf, where it does not wrap anything.
+func (c *checker) checkWrapVerb(n *ast.CallExpr) {
+	name, isMethod, ok := c.flooerrCallee(n)
+	if !ok {
+		return
+	}
+
+	formatArg := -1
+	switch {
+	case !isMethod && name == "ErrorF", isMethod && name == "Errorf":
+		formatArg = 0
+	case !isMethod && name == "WrapF", isMethod && name == "Wrapf":
+		formatArg = 1
+	}
+	if formatArg < 0 || len(n.Args) <= formatArg {
+		return
+	}
+
+	format, ok := c.stringConst(n.Args[formatArg])
+	if !ok || !hasWrapVerb(format) {
+		return
+	}
+
+	fix := "pass the cause to WrapF and use %v in the format"
+	if formatArg == 1 {
+		fix = "the cause is already wrapped; use %v in the format"
+	}
+	c.report(Diagnostic{
+		Pos:     n.Args[formatArg].Pos(),
+		Check:   checkWrapVerb,
+		Message: fmt.Sprintf("%s does not support the %%w verb", name),
+		Fix:     fix,
+	})
+}
+
+// checkCodeArgs flags string literals passed where an error code is
+// expected.
+func (c *checker) checkCodeArgs(n *ast.CallExpr) {
+	sig, ok := c.pkg.Info.TypeOf(n.Fun).(*types.Signature)
+	if !ok {
+		return
+	}
+	name, isMethod, isFlooerr := c.flooerrCallee(n)
+
+	for i, arg := range n.Args {
+		if i >= sig.Params().Len() {
+			break
+		}
+		param := sig.Params().At(i)
+		switch {
+		case isCodeType(param.Type()):
+			c.checkCodeLiteral(arg, false)
+		case isFlooerr && isMethod && name == "WithCode" && i == 0,
+			isFlooerr && !isMethod && name == "HasCode" && i == 1:
+			c.checkCodeLiteral(arg, true)
+		}
+	}
+}
+
+// checkCodeCompare flags comparisons between a code and a string literal.
+func (c *checker) checkCodeCompare(n *ast.BinaryExpr) {
+	if n.Op != token.EQL && n.Op != token.NEQ {
+		return
+	}
+	if isCodeType(c.pkg.Info.TypeOf(n.X)) {
+		c.checkCodeLiteral(n.Y, false)
+	} else if isCodeType(c.pkg.Info.TypeOf(n.Y)) {
+		c.checkCodeLiteral(n.X, false)
+	}
+}
+
+// checkCodeLiteral reports a string literal code. asString is set when the
+// literal is passed as a plain string, so replacements need .String().
+func (c *checker) checkCodeLiteral(expr ast.Expr, asString bool) {
+	lit, ok := ast.Unparen(expr).(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING || len(c.codes) == 0 {
+		return
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil || value == "" {
+		return
+	}
+
+	replacement := func(k codeConst) string {
+		ref := c.constRef(k)
+		if asString {
+			ref += ".String()"
+		}
+		return ref
+	}
+
+	if known, ok := c.codes[value]; ok {
+		c.report(Diagnostic{
+			Pos:     lit.Pos(),
+			Check:   checkCode,
+			Message: fmt.Sprintf("literal code %q duplicates constant %s", value, known),
+			Fix:     "use " + known.String(),
+			Edits:   []TextEdit{{Pos: lit.Pos(), End: lit.End(), NewText: replacement(known)}},
+		})
+		return
+	}
+
+	d := Diagnostic{
+		Pos:     lit.Pos(),
+		Check:   checkCode,
+		Message: fmt.Sprintf("literal code %q does not match any known code constant", value),
+	}
+	if nearest, ok := nearestCode(value, c.codes); ok {
+		known := c.codes[nearest]
+		d.Fix = fmt.Sprintf("did you mean %s (%q)?", known, nearest)
+		d.Edits = []TextEdit{{Pos: lit.Pos(), End: lit.End(), NewText: replacement(known)}}
+	}
+	c.report(d)
+}
+
+// checkUnbuiltStmt flags builder chains used as statements, e.g.
+// `flooerr.Message("x").WithCode("y")` without a terminal Build.
+func (c *checker) checkUnbuiltStmt(n *ast.ExprStmt) {
+	if !isErrPropsType(c.pkg.Info.TypeOf(n.X)) {
+		return
+	}
+	// b.WithContext(...) as a statement mutates a builder held elsewhere.
+	if _, ok := chainRoot(n.X).(*ast.Ident); ok {
+		return
+	}
+	c.report(Diagnostic{
+		Pos:     n.Pos(),
+		Check:   checkUnbuilt,
+		Message: "error builder is created but never built",
+		Fix:     "call Build, Error, Errorf, Wrap, Wrapf or WrapIf, or return the error",
+	})
+}
+
+// checkUnbuiltVars flags local builder variables that never reach a
+// terminal method and never escape the function.
+func (c *checker) checkUnbuiltVars() {
+	used := make(map[*types.Var]bool)
+	defs := make(map[*types.Var]*ast.Ident)
+
+	for ident, obj := range c.pkg.Info.Defs {
+		v, ok := obj.(*types.Var)
+		if !ok || v.IsField() || v.Parent() == nil || v.Parent() == c.pkg.Types.Scope() {
+			continue
+		}
+		if _, isField := c.parents[ident].(*ast.Field); isField {
+			continue // parameters and results belong to the caller
+		}
+		if isErrPropsType(v.Type()) {
+			defs[v] = ident
+		}
+	}
+	for ident, obj := range c.pkg.Info.Uses {
+		v, ok := obj.(*types.Var)
+		if !ok || defs[v] == nil {
+			continue
+		}
+		if c.builtOrEscapes(ident) {
+			used[v] = true
+		}
+	}
+
+	for v, ident := range defs {
+		if used[v] || ident.Name == "_" {
+			continue
+		}
+		c.report(Diagnostic{
+			Pos:     ident.Pos(),
+			Check:   checkUnbuilt,
+			Message: fmt.Sprintf("error builder %s is created but never built", ident.Name),
+			Fix:     "call Build, Error, Errorf, Wrap, Wrapf or WrapIf on it, or remove it",
+		})
+	}
+}
+
+// builtOrEscapes follows a builder expression up its method chain and
+// reports whether it ends in a terminal method or leaves the chain.
+func (c *checker) builtOrEscapes(expr ast.Node) bool {
+	for {
+		parent := c.parents[expr]
+		switch p := parent.(type) {
+		case *ast.ParenExpr:
+			expr = p
+		case *ast.SelectorExpr:
+			if p.X != expr {
+				return true
+			}
+			call, ok := c.parents[p].(*ast.CallExpr)
+			if !ok || call.Fun != p {
+				return true
+			}
+			if terminalMethods[p.Sel.Name] {
+				return true
+			}
+			if !isErrPropsType(c.pkg.Info.TypeOf(call)) {
+				return true
+			}
+			expr = call
+		case *ast.ExprStmt:
+			return false
+		case *ast.AssignStmt:
+			// Assigning to another variable hands the builder over.
+			for _, lhs := range p.Lhs {
+				if lhs == expr {
+					return false
+				}
+			}
+			return true
+		default:
+			return true
+		}
+	}
+}
+
+// flooerrCallee resolves the called function or ErrProps method.
+func (c *checker) flooerrCallee(call *ast.CallExpr) (name string, isMethod bool, ok bool) {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return "", false, false
+	}
+
+	fn, isFunc := c.pkg.Info.Uses[ident].(*types.Func)
+	if !isFunc || fn.Pkg() == nil {
+		return "", false, false
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() != nil {
+		return fn.Name(), true, isErrPropsType(sig.Recv().Type())
+	}
+	return fn.Name(), false, fn.Pkg().Path() == flooerrPath
+}
+
+func (c *checker) isNil(expr ast.Expr) bool {
+	tv, ok := c.pkg.Info.Types[expr]
+	return ok && tv.IsNil()
+}
+
+func (c *checker) stringConst(expr ast.Expr) (string, bool) {
+	tv, ok := c.pkg.Info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// qualify returns name qualified with the file's import name for flooerr.
+func (c *checker) qualify(name string) string {
+	if c.pkg.Types.Path() == flooerrPath {
+		return name
+	}
+	for _, spec := range c.file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == flooerrPath {
+			if spec.Name != nil {
+				return spec.Name.Name + "." + name
+			}
+			break
+		}
+	}
+	return "flooerr." + name
+}
+
+// constRef returns a reference to k valid in the current file.
+func (c *checker) constRef(k codeConst) string {
+	if k.Path == c.pkg.Types.Path() {
+		return k.Name
+	}
+	for _, spec := range c.file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == k.Path && spec.Name != nil {
+			return spec.Name.Name + "." + k.Name
+		}
+	}
+	return k.String()
+}
+
+func (c *checker) source(expr ast.Node) string {
+	var b strings.Builder
+	start := c.pkg.Fset.Position(expr.Pos())
+	end := c.pkg.Fset.Position(expr.End())
+	if src, ok := c.readFile(start.Filename); ok && end.Offset <= len(src) {
+		b.Write(src[start.Offset:end.Offset])
+	}
+	return b.String()
+}
+
+func (c *checker) readFile(name string) ([]byte, bool) {
+	if src, ok := c.sources[name]; ok {
+		return src, true
+	}
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return nil, false
+	}
+	c.sources[name] = src
+	return src, true
+}
+
+func (c *checker) sourceList(exprs []ast.Expr) string {
+	parts := make([]string, len(exprs))
+	for i, expr := range exprs {
+		parts[i] = c.source(expr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// knownCodes collects the code constants declared in pkg and its transitive
+// imports, keyed by value.
+func knownCodes(pkg *types.Package) map[string]codeConst {
+	codes := make(map[string]codeConst)
+	seen := make(map[*types.Package]bool)
+	var walk func(p *types.Package)
+	walk = func(p *types.Package) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		scope := p.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.Const)
+			if !ok || !isCodeType(obj.Type()) || obj.Val().Kind() != constant.String {
+				continue
+			}
+			if p != pkg && !obj.Exported() {
+				continue
+			}
+			value := constant.StringVal(obj.Val())
+			if _, exists := codes[value]; !exists {
+				codes[value] = codeConst{Path: p.Path(), Pkg: p.Name(), Name: name}
+			}
+		}
+		for _, imp := range p.Imports() {
+			walk(imp)
+		}
+	}
+	walk(pkg)
+	return codes
+}
+
+// nearestCode returns the known code closest to value if it is within a
+// small edit distance.
+func nearestCode(value string, codes map[string]codeConst) (string, bool) {
+	best, bestDist := "", 3
+	for code := range codes {
+		dist := editDistance(strings.ToUpper(value), strings.ToUpper(code))
+		if dist < bestDist || dist == bestDist && best != "" && code < best {
+			best, bestDist = code, dist
+		}
+	}
+	return best, best != ""
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// hasWrapVerb reports whether format contains a %w verb.
+func hasWrapVerb(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format); i++ {
+			ch := format[i]
+			if ch == '%' {
+				break
+			}
+			if strings.IndexByte("+-# 0123456789.*[]", ch) >= 0 {
+				continue
+			}
+			if ch == 'w' {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// chainRoot returns the expression a method chain starts from.
+func chainRoot(expr ast.Expr) ast.Expr {
+	for {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.CallExpr:
+			sel, ok := ast.Unparen(e.Fun).(*ast.SelectorExpr)
+			if !ok {
+				return e
+			}
+			if _, isPkg := sel.X.(*ast.Ident); isPkg && builderFuncs[sel.Sel.Name] {
+				return e
+			}
+			if _, isPkg := sel.X.(*ast.Ident); isPkg && chainFuncs[sel.Sel.Name] && len(e.Args) > 0 {
+				expr = e.Args[0]
+				continue
+			}
+			expr = sel.X
+		default:
+			return e
+		}
+	}
+}
+
+func isFlooErrType(t types.Type) bool {
+	return isNamed(t, flooerrPath, "FlooErr")
+}
+
+func isCodeType(t types.Type) bool {
+	return isNamed(t, internalPath, "Code")
+}
+
+func isErrPropsType(t types.Type) bool {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return isNamed(ptr.Elem(), internalPath, "ErrProps")
+	}
+	return false
+}
+
+func isNamed(t types.Type, path, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == path && obj.Name() == name
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// listedPackage is the subset of `go list -json` output the checker needs.
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Export     string
+	ImportMap  map[string]string
+	DepOnly    bool
+	Error      *struct{ Err string }
+}
+
+// checkedPackage is a parsed and type-checked package ready for analysis.
+type checkedPackage struct {
+	Path  string
+	Fset  *token.FileSet
+	Files []*ast.File
+	Types *types.Package
+	Info  *types.Info
+}
+
+// loadPackages lists the patterns with the go command and type-checks every
+// matched package against the export data of its dependencies.
+func loadPackages(dir string, patterns ...string) ([]*checkedPackage, error) {
+	args := append([]string{"list", "-e", "-json", "-export", "-deps", "--"}, patterns...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %v: %s", err, stderr.String())
+	}
+
+	var listed []*listedPackage
+	exports := make(map[string]string)
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for decoder.More() {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, fmt.Errorf("go list: %w", err)
+		}
+		if pkg.Export != "" {
+			exports[pkg.ImportPath] = pkg.Export
+		}
+		if !pkg.DepOnly {
+			listed = append(listed, &pkg)
+		}
+	}
+
+	var pkgs []*checkedPackage
+	for _, pkg := range listed {
+		if pkg.Error != nil {
+			return nil, fmt.Errorf("%s: %s", pkg.ImportPath, pkg.Error.Err)
+		}
+		checked, err := checkPackage(pkg, exports)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = append(pkgs, checked)
+	}
+	return pkgs, nil
+}
+
+func checkPackage(pkg *listedPackage, exports map[string]string) (*checkedPackage, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	lookup := func(path string) (io.ReadCloser, error) {
+		if mapped, ok := pkg.ImportMap[path]; ok {
+			path = mapped
+		}
+		export, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(export)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "gc", lookup)}
+	typesPkg, err := conf.Check(pkg.ImportPath, fset, files, info)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pkg.ImportPath, err)
+	}
+
+	return &checkedPackage{
+		Path:  pkg.ImportPath,
+		Fset:  fset,
+		Files: files,
+		Types: typesPkg,
+		Info:  info,
+	}, nil
+}
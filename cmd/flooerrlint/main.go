@@ -0,0 +1,154 @@
+// Command flooerrlint reports common misuse of the flooerr package:
+//
+//   - assert:   x.(flooerr.FlooErr) assertions and type switch cases, which
+//     miss wrapped errors
+//   - nilwrap:  Wrap/WrapF and builder Wrap/Wrapf called with a nil cause
+//   - unbuilt:  error builders that are created but never built
+//   - code:     string literals used as error codes that duplicate or do not
+//     match a known code constant
+//   - wrapverb: %w in ErrorF, WrapF, Errorf and Wrapf formats, which are
+//     formatted with fmt.Sprintf and do not wrap
+//
+// Usage:
+//
+//	flooerrlint [-checks assert,nilwrap,...] [-fix] [packages]
+//
+// Diagnostics are printed vet-style as file:line:col. With -fix, suggested
+// edits are applied in place. The exit status is 3 when findings are
+// reported.
+//
+// A //flooerrlint:ignore comment, optionally followed by check names,
+// suppresses findings on its line and the next one. The flooerr package
+// itself is not checked.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	code, err := run(os.Args[1:], ".", os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "flooerrlint:", err)
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+func run(args []string, dir string, out io.Writer) (int, error) {
+	flags := flag.NewFlagSet("flooerrlint", flag.ContinueOnError)
+	checks := flags.String("checks", strings.Join(allChecks, ","), "comma-separated checks to run")
+	fix := flags.Bool("fix", false, "apply suggested fixes")
+	if err := flags.Parse(args); err != nil {
+		return 0, err
+	}
+
+	enabled := make(map[string]bool)
+	for _, name := range strings.Split(*checks, ",") {
+		name = strings.TrimSpace(name)
+		if !isKnownCheck(name) {
+			return 0, fmt.Errorf("unknown check %q", name)
+		}
+		enabled[name] = true
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	pkgs, err := loadPackages(dir, patterns...)
+	if err != nil {
+		return 0, err
+	}
+
+	found := 0
+	for _, pkg := range pkgs {
+		diags := checkPkg(pkg, enabled)
+		found += len(diags)
+		for _, d := range diags {
+			fmt.Fprintln(out, formatDiagnostic(pkg, d))
+		}
+		if *fix {
+			if err := applyFixes(pkg, diags); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if found > 0 {
+		return 3, nil
+	}
+	return 0, nil
+}
+
+func isKnownCheck(name string) bool {
+	for _, check := range allChecks {
+		if check == name {
+			return true
+		}
+	}
+	return false
+}
+
+func formatDiagnostic(pkg *checkedPackage, d Diagnostic) string {
+	line := fmt.Sprintf("%s: %s (%s)", pkg.Fset.Position(d.Pos), d.Message, d.Check)
+	if d.Fix != "" {
+		line += "\n\tsuggested fix: " + d.Fix
+	}
+	return line
+}
+
+// applyFixes rewrites the files of pkg with the edits of diags.
+func applyFixes(pkg *checkedPackage, diags []Diagnostic) error {
+	for name, edits := range editsByFile(pkg, diags) {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, applyEdits(src, edits), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// offsetEdit is a TextEdit resolved to byte offsets within a file.
+type offsetEdit struct {
+	start, end int
+	text       string
+}
+
+func editsByFile(pkg *checkedPackage, diags []Diagnostic) map[string][]offsetEdit {
+	edits := make(map[string][]offsetEdit)
+	for _, d := range diags {
+		for _, edit := range d.Edits {
+			start := pkg.Fset.Position(edit.Pos)
+			edits[start.Filename] = append(edits[start.Filename], offsetEdit{
+				start: start.Offset,
+				end:   pkg.Fset.Position(edit.End).Offset,
+				text:  edit.NewText,
+			})
+		}
+	}
+	return edits
+}
+
+// applyEdits applies edits back to front so earlier offsets stay valid,
+// skipping any edit that overlaps one already applied.
+func applyEdits(src []byte, edits []offsetEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), src...)
+	limit := len(out)
+	for _, edit := range edits {
+		if edit.end > limit {
+			continue
+		}
+		out = append(out[:edit.start], append([]byte(edit.text), out[edit.end:]...)...)
+		limit = edit.start
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, actual, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading golden file: %v", err)
+	}
+	if string(expected) != string(actual) {
+		t.Errorf("Output does not match %s (run with -update to rewrite):\n%s", path, actual)
+	}
+}
+
+func TestRun_Golden(t *testing.T) {
+	var out bytes.Buffer
+	code, err := run([]string{"./testdata/src/a"}, ".", &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if code != 3 {
+		t.Errorf("Expected exit code 3, got %d", code)
+	}
+
+	dir, err := filepath.Abs("testdata/src/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	normalized := strings.ReplaceAll(out.String(), dir+string(filepath.Separator), "")
+	assertGolden(t, "a.golden", []byte(normalized))
+}
+
+func TestRun_ChecksFlag(t *testing.T) {
+	var out bytes.Buffer
+	_, err := run([]string{"-checks", "nilwrap", "./testdata/src/a"}, ".", &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		if !strings.HasSuffix(line, "(nilwrap)") {
+			t.Errorf("Expected only nilwrap diagnostics, got %q", line)
+		}
+	}
+}
+
+// TestRun_Repository keeps the repository itself free of findings.
+func TestRun_Repository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("type-checks the whole repository")
+	}
+
+	var out bytes.Buffer
+	code, err := run([]string{"./..."}, "../..", &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if code != 0 || out.Len() != 0 {
+		t.Errorf("Expected no findings in the repository, got:\n%s", out.String())
+	}
+}
+
+func TestRun_UnknownCheck(t *testing.T) {
+	if _, err := run([]string{"-checks", "nope"}, ".", &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for unknown check")
+	}
+}
+
+func TestFixes_Golden(t *testing.T) {
+	pkgs, err := loadPackages(".", "./testdata/src/a")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	pkg := pkgs[0]
+	diags := checkPkg(pkg, map[string]bool{checkAssert: true, checkNilWrap: true, checkCode: true})
+	for name, edits := range editsByFile(pkg, diags) {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertGolden(t, filepath.Base(name)+".fixed.golden", applyEdits(src, edits))
+	}
+}
+
+func TestApplyEdits_Overlap(t *testing.T) {
+	src := []byte("abcdef")
+	edits := []offsetEdit{
+		{start: 1, end: 3, text: "X"},
+		{start: 2, end: 4, text: "Y"},
+	}
+
+	if actual := string(applyEdits(src, edits)); actual != "abYef" {
+		t.Errorf("Expected 'abYef', got '%s'", actual)
+	}
+}
+
+func TestHasWrapVerb(t *testing.T) {
+	tests := map[string]bool{
+		"failed: %w":   true,
+		"failed: %+w":  true,
+		"failed: %v":   false,
+		"100%% w":      false,
+		"%[1]w":        true,
+		"trailing %":   false,
+		"%d items %%w": false,
+	}
+
+	for format, expected := range tests {
+		if actual := hasWrapVerb(format); actual != expected {
+			t.Errorf("hasWrapVerb(%q): expected %v, got %v", format, expected, actual)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	if d := editDistance("USER_NOTFOUND", "USER_NOT_FOUND"); d != 1 {
+		t.Errorf("Expected distance 1, got %d", d)
+	}
+	if d := editDistance("", "abc"); d != 3 {
+		t.Errorf("Expected distance 3, got %d", d)
+	}
+}
@@ -0,0 +1,87 @@
+package a
+
+import (
+	"errors"
+
+	"core-common-go/flooerr"
+)
+
+const (
+	CodeUserNotFound flooerr.ErrCode = "USER_NOT_FOUND"
+	CodeInvalidInput flooerr.ErrCode = "INVALID_INPUT"
+)
+
+func assertions(err error) {
+	if flooErr, ok := err.(flooerr.FlooErr); ok {
+		_ = flooErr.Code()
+	}
+
+	_ = err.(flooerr.FlooErr).Message()
+
+	switch err.(type) {
+	case flooerr.FlooErr:
+	}
+
+	if flooErr, ok := flooerr.AsFlooErr(err); ok {
+		_ = flooErr
+	}
+}
+
+func nilWraps(err error) error {
+	_ = flooerr.Wrap(nil, "no cause")
+	_ = flooerr.WrapF(nil, "no cause %d", 1)
+	_ = flooerr.Message("m").Wrap(nil, "no cause")
+	_ = flooerr.Message("m").Wrapf(nil, "no cause %s", "x")
+	return flooerr.Wrap(err, "fine")
+}
+
+func unbuilt(err error) error {
+	flooerr.Message("forgotten").WithCode("INVALID_INPUT")
+
+	forgotten := flooerr.Message("forgotten")
+	forgotten.WithContext("k", "v")
+
+	built := flooerr.Message("built")
+	built.WithContext("k", "v")
+	flooerr.With(built, key, "v")
+	flooerr.With(flooerr.Message("forgotten"), key, "v")
+
+	handedOver := flooerr.Message("handed over")
+	use(handedOver)
+
+	return built.Build(err, "built")
+}
+
+func use(any) {}
+
+var key = flooerr.NewKey[string]("k")
+
+func codes(err error) bool {
+	_ = flooerr.Code("USER_NOT_FOUND").Error(nil, "duplicate")
+	_ = flooerr.Message("m").WithCode("USER_NOTFOUND").Error(nil, "typo")
+	_ = flooerr.Message("m").WithCode("SOMETHING_ELSE").Error(nil, "unknown")
+	_ = flooerr.Code(CodeInvalidInput).Error(nil, "constant")
+	return flooerr.HasCode(err, "INVALID_INPUT") || flooerr.GetCode(err) == "INVALID_INPT"
+}
+
+func wrapVerbs(err error) {
+	_ = flooerr.ErrorF("failed: %w", err)
+	_ = flooerr.WrapF(err, "failed: %w", err)
+	_ = flooerr.Message("m").Errorf("failed: %+w", err)
+	_ = flooerr.ErrorF("100%% fine: %v", err)
+	_ = errors.New("unrelated")
+}
+
+func ignored(err error) {
+	//flooerrlint:ignore assert
+	_ = err.(flooerr.FlooErr)
+	_ = err.(flooerr.FlooErr) //flooerrlint:ignore
+	//flooerrlint:ignore nilwrap
+	_ = err.(flooerr.FlooErr)
+}
+
+func anyAssertion(v any) {
+	if flooErr, ok := v.(flooerr.FlooErr); ok {
+		_ = flooErr.Code()
+	}
+}
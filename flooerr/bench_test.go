@@ -0,0 +1,102 @@
+package flooerr
+
+import (
+	"errors"
+	"testing"
+)
+
+var (
+	errBenchNotFound = Sentinel("NOT_FOUND", "not found")
+	errBenchCause    = errors.New("cause")
+	benchErr         error
+	benchBool        bool
+	benchCode        ErrCode
+)
+
+//go:noinline
+func returnSentinel() error {
+	return errBenchNotFound
+}
+
+func buildStackless() error {
+	return Code("NOT_FOUND").WithStackTrace(false).Error(nil, "not found")
+}
+
+func buildWithStack() error {
+	return Code("NOT_FOUND").Error(nil, "not found")
+}
+
+func TestAllocs(t *testing.T) {
+	tests := []struct {
+		name string
+		max  float64
+		fn   func()
+	}{
+		{"sentinel return and match", 0, func() {
+			benchBool = errors.Is(returnSentinel(), errBenchNotFound)
+		}},
+		{"stackless build", 1, func() { benchErr = buildStackless() }},
+		{"interned stack build", 1, func() { benchErr = buildWithStack() }},
+		{"wrap", 1, func() { benchErr = Wrap(errBenchCause, "wrapped") }},
+	}
+
+	for _, tt := range tests {
+		if allocs := testing.AllocsPerRun(100, tt.fn); allocs > tt.max {
+			t.Errorf("%s: expected at most %v allocations, got %v", tt.name, tt.max, allocs)
+		}
+	}
+}
+
+func BenchmarkSentinel(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchBool = errors.Is(returnSentinel(), errBenchNotFound)
+	}
+}
+
+func BenchmarkBuild_Stackless(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = buildStackless()
+	}
+}
+
+func BenchmarkBuild_Stack(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = buildWithStack()
+	}
+}
+
+func BenchmarkBuild_ReturnTrace(b *testing.B) {
+	defer SetStackMode(SetStackMode(StackReturn))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = buildWithStack()
+	}
+}
+
+func BenchmarkBuild_Context(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = Code("NOT_FOUND").
+			WithContext("key", "user:42").
+			WithSDC("trace_id", "trace_123").
+			Error(nil, "not found")
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchErr = Wrap(errBenchCause, "wrapped")
+	}
+}
+
+func BenchmarkGetCode(b *testing.B) {
+	err := Wrap(buildStackless(), "wrapped")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchCode = GetCode(err)
+	}
+}
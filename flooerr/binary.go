@@ -0,0 +1,687 @@
+package flooerr
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Binary encoding layout (version 1):
+//
+//	magic "FLE" | version byte | fields...
+//
+// Fields are protobuf-style: a uvarint tag (field number << 3 | wire type)
+// followed by a uvarint (wire type 0), 8 little-endian bytes (wire type 1)
+// or a uvarint length and that many bytes (wire type 2). Decoders skip
+// fields they do not know, so new fields can be added without a version
+// bump; the version changes only for incompatible layouts.
+//
+// The top level holds one layer field per error in the chain, outermost
+// first. Layers hold the code, messages, context and SDC entries, frames and
+// the sentinel flag. Context values keep a small typed set: strings,
+// signed integers (decoded as int64), unsigned integers (uint64), floats
+// (float64), booleans, byte slices, nil, time.Time and time.Duration. Other
+// values are encoded as their %v text.
+const (
+	binaryMagic   = "FLE"
+	binaryVersion = 1
+
+	// DefaultMaxBinarySize is the default size limit for encoding and
+	// decoding.
+	DefaultMaxBinarySize = 64 << 10
+	// DefaultMaxBinaryLayers is the default limit on decoded chain length.
+	DefaultMaxBinaryLayers = 64
+)
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// Top-level fields.
+const fieldLayer = 1
+
+// Layer fields.
+const (
+	layerForeign    = 1
+	layerCode       = 2
+	layerMessage    = 3
+	layerErrMessage = 4
+	layerContext    = 5
+	layerSDC        = 6
+	layerFrame      = 7
+	layerPC         = 8
+	layerSentinel   = 9
+	layerOp         = 10
+	layerID         = 11
+	layerTime       = 12
+	layerPublic     = 13
+)
+
+// Entry fields, shared by context and SDC entries.
+const (
+	entryKey   = 1
+	entryValue = 2
+)
+
+// Frame fields.
+const (
+	frameFunction = 1
+	frameFile     = 2
+	frameLine     = 3
+)
+
+// Value fields; exactly one is set.
+const (
+	valueString   = 1
+	valueInt      = 2
+	valueUint     = 3
+	valueFloat    = 4
+	valueBool     = 5
+	valueBytes    = 6
+	valueNil      = 7
+	valueTime     = 8
+	valueDuration = 9
+)
+
+var (
+	// ErrBinaryTooLarge is returned when an encoding exceeds MaxSize.
+	ErrBinaryTooLarge = errors.New("flooerr: binary encoding exceeds size limit")
+	// ErrBinaryInvalid is returned for malformed input.
+	ErrBinaryInvalid = errors.New("flooerr: invalid binary encoding")
+)
+
+// BinaryOptions configures the binary encoding. The zero value uses
+// symbolized frames and the default limits.
+type BinaryOptions struct {
+	// RawFrames encodes program counters instead of symbolized frames.
+	// They are much smaller but only meaningful to the same binary.
+	RawFrames bool
+	// NoFrames omits stack traces.
+	NoFrames bool
+	// MaxSize limits the encoded size. When an encoding is too large it is
+	// retried without frames before failing with ErrBinaryTooLarge.
+	MaxSize int
+	// MaxLayers limits the number of decoded layers.
+	MaxLayers int
+}
+
+// MarshalBinary encodes err with the default options.
+func MarshalBinary(err error) ([]byte, error) {
+	return BinaryOptions{}.Marshal(err)
+}
+
+// UnmarshalBinary decodes an error encoded by MarshalBinary with the default
+// options.
+func UnmarshalBinary(data []byte) (error, error) {
+	return BinaryOptions{}.Unmarshal(data)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (e *err) MarshalBinary() ([]byte, error) {
+	return MarshalBinary(e)
+}
+
+// Marshal encodes the whole chain of err.
+func (o BinaryOptions) Marshal(err error) ([]byte, error) {
+	if err == nil {
+		return nil, fmt.Errorf("flooerr: cannot encode a nil error")
+	}
+	maxSize := o.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBinarySize
+	}
+
+	data := o.encode(err)
+	if len(data) > maxSize && !o.NoFrames {
+		o.NoFrames = true
+		data = o.encode(err)
+	}
+	if len(data) > maxSize {
+		return nil, ErrBinaryTooLarge
+	}
+	return data, nil
+}
+
+func (o BinaryOptions) encode(err error) []byte {
+	out := append([]byte(binaryMagic), binaryVersion)
+	for _, layer := range UnwrapChain(err) {
+		out = appendBytesField(out, fieldLayer, o.encodeLayer(layer))
+	}
+	return out
+}
+
+func (o BinaryOptions) encodeLayer(layer error) []byte {
+	var out []byte
+	flooErr, ok := asLayer(layer)
+	if !ok {
+		out = appendVarintField(out, layerForeign, 1)
+		return appendStringField(out, layerErrMessage, layer.Error())
+	}
+
+	out = appendStringField(out, layerCode, flooErr.Code().String())
+	out = appendStringField(out, layerMessage, flooErr.Message())
+	out = appendStringField(out, layerErrMessage, layerText(flooErr))
+	for _, key := range sortedKeys(flooErr.Context()) {
+		entry := appendStringField(nil, entryKey, key)
+		entry = appendBytesField(entry, entryValue, encodeValue(flooErr.Context()[key]))
+		out = appendBytesField(out, layerContext, entry)
+	}
+	for _, key := range sortedKeys(flooErr.SDC()) {
+		entry := appendStringField(nil, entryKey, key)
+		entry = appendStringField(entry, entryValue, flooErr.SDC()[key])
+		out = appendBytesField(out, layerSDC, entry)
+	}
+
+	e, isErr := flooErr.(*err)
+	switch {
+	case o.NoFrames:
+	case o.RawFrames && isErr && len(e.stackTracePTR) > 0:
+		for _, pc := range e.stackTracePTR {
+			out = appendVarintField(out, layerPC, uint64(pc))
+		}
+	default:
+		for _, frame := range flooErr.StackTrace() {
+			f := appendStringField(nil, frameFunction, frame.Function)
+			f = appendStringField(f, frameFile, frame.File)
+			f = appendVarintField(f, frameLine, uint64(frame.Line))
+			out = appendBytesField(out, layerFrame, f)
+		}
+	}
+	if isErr && e.sentinel {
+		out = appendVarintField(out, layerSentinel, 1)
+	}
+	if op := opOf(flooErr); op != "" {
+		out = appendStringField(out, layerOp, op)
+	}
+	if id := idOf(flooErr); id != (ErrorID{}) {
+		out = appendBytesField(out, layerID, id[:])
+	}
+	if built := timeOf(flooErr); !built.IsZero() {
+		out = appendFixed64Field(out, layerTime, uint64(built.UnixNano()))
+	}
+	if public := publicOf(flooErr); public != "" {
+		out = appendStringField(out, layerPublic, public)
+	}
+	return out
+}
+
+func encodeValue(value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return appendVarintField(nil, valueNil, 1)
+	case string:
+		return appendStringField(nil, valueString, v)
+	case bool:
+		b := uint64(0)
+		if v {
+			b = 1
+		}
+		return appendVarintField(nil, valueBool, b)
+	case int:
+		return appendVarintField(nil, valueInt, zigzag(int64(v)))
+	case int8:
+		return appendVarintField(nil, valueInt, zigzag(int64(v)))
+	case int16:
+		return appendVarintField(nil, valueInt, zigzag(int64(v)))
+	case int32:
+		return appendVarintField(nil, valueInt, zigzag(int64(v)))
+	case int64:
+		return appendVarintField(nil, valueInt, zigzag(v))
+	case uint:
+		return appendVarintField(nil, valueUint, uint64(v))
+	case uint8:
+		return appendVarintField(nil, valueUint, uint64(v))
+	case uint16:
+		return appendVarintField(nil, valueUint, uint64(v))
+	case uint32:
+		return appendVarintField(nil, valueUint, uint64(v))
+	case uint64:
+		return appendVarintField(nil, valueUint, v)
+	case float32:
+		return appendFixed64Field(nil, valueFloat, math.Float64bits(float64(v)))
+	case float64:
+		return appendFixed64Field(nil, valueFloat, math.Float64bits(v))
+	case []byte:
+		return appendBytesField(nil, valueBytes, v)
+	case time.Time:
+		text, err := v.MarshalText()
+		if err != nil {
+			return appendStringField(nil, valueString, v.String())
+		}
+		return appendBytesField(nil, valueTime, text)
+	case time.Duration:
+		return appendVarintField(nil, valueDuration, zigzag(int64(v)))
+	default:
+		return appendStringField(nil, valueString, fmt.Sprintf("%v", v))
+	}
+}
+
+// Unmarshal decodes an encoded chain. FlooErr layers decode to FlooErrs
+// with their stack traces; other layers decode to plain errors with their
+// message and cause.
+func (o BinaryOptions) Unmarshal(data []byte) (error, error) {
+	maxSize := o.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBinarySize
+	}
+	maxLayers := o.MaxLayers
+	if maxLayers <= 0 {
+		maxLayers = DefaultMaxBinaryLayers
+	}
+
+	if len(data) > maxSize {
+		return nil, ErrBinaryTooLarge
+	}
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("%w: missing header", ErrBinaryInvalid)
+	}
+	if version := data[len(binaryMagic)]; version != binaryVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBinaryInvalid, version)
+	}
+
+	var layers []error
+	r := wireReader{data: data[len(binaryMagic)+1:]}
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return nil, err
+		}
+		if field != fieldLayer || wire != wireBytes {
+			if err := r.skip(wire); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(layers) == maxLayers {
+			return nil, fmt.Errorf("%w: more than %d layers", ErrBinaryInvalid, maxLayers)
+		}
+		payload, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		layer, err := decodeLayer(payload)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("%w: no layers", ErrBinaryInvalid)
+	}
+
+	// Link causes from the root up.
+	for i := len(layers) - 2; i >= 0; i-- {
+		switch layer := layers[i].(type) {
+		case *err:
+			layer.cause = layers[i+1]
+		case *decodedError:
+			layer.cause = layers[i+1]
+		}
+	}
+	return layers[0], nil
+}
+
+// decodedError is a decoded layer that was not a FlooErr.
+type decodedError struct {
+	message string
+	cause   error
+}
+
+func (e *decodedError) Error() string {
+	return e.message
+}
+
+func (e *decodedError) Unwrap() error {
+	return e.cause
+}
+
+func decodeLayer(data []byte) (error, error) {
+	decoded := &err{
+		context: make(map[string]any),
+		sdc:     make(map[string]string),
+	}
+	foreign := false
+
+	r := wireReader{data: data}
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == layerForeign && wire == wireVarint:
+			v, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			foreign = v != 0
+		case field == layerCode && wire == wireBytes:
+			s, err := r.string()
+			if err != nil {
+				return nil, err
+			}
+			decoded.code = ErrCode(s)
+		case field == layerMessage && wire == wireBytes:
+			if decoded.message, err = r.string(); err != nil {
+				return nil, err
+			}
+		case field == layerErrMessage && wire == wireBytes:
+			if decoded.errMessage, err = r.string(); err != nil {
+				return nil, err
+			}
+		case field == layerContext && wire == wireBytes:
+			key, value, err := decodeContextEntry(&r)
+			if err != nil {
+				return nil, err
+			}
+			decoded.context[key] = value
+		case field == layerSDC && wire == wireBytes:
+			key, value, err := decodeSDCEntry(&r)
+			if err != nil {
+				return nil, err
+			}
+			decoded.sdc[key] = value
+		case field == layerFrame && wire == wireBytes:
+			frame, err := decodeFrame(&r)
+			if err != nil {
+				return nil, err
+			}
+			decoded.stackTrace = append(decoded.stackTrace, frame)
+		case field == layerPC && wire == wireVarint:
+			pc, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			decoded.stackTracePTR = append(decoded.stackTracePTR, uintptr(pc))
+		case field == layerSentinel && wire == wireVarint:
+			v, err := r.uvarint()
+			if err != nil {
+				return nil, err
+			}
+			decoded.sentinel = v != 0
+		case field == layerID && wire == wireBytes:
+			id, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			copy(decoded.id[:], id)
+		case field == layerTime && wire == wireFixed64:
+			v, err := r.fixed64()
+			if err != nil {
+				return nil, err
+			}
+			decoded.time = time.Unix(0, int64(v))
+		case field == layerOp && wire == wireBytes:
+			if decoded.op, err = r.string(); err != nil {
+				return nil, err
+			}
+		case field == layerPublic && wire == wireBytes:
+			if decoded.publicMessage, err = r.string(); err != nil {
+				return nil, err
+			}
+		default:
+			if err := r.skip(wire); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if foreign {
+		return &decodedError{message: decoded.errMessage}, nil
+	}
+	return decoded, nil
+}
+
+func decodeContextEntry(parent *wireReader) (string, any, error) {
+	payload, err := parent.bytes()
+	if err != nil {
+		return "", nil, err
+	}
+	var key string
+	var value any
+	r := wireReader{data: payload}
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return "", nil, err
+		}
+		switch {
+		case field == entryKey && wire == wireBytes:
+			if key, err = r.string(); err != nil {
+				return "", nil, err
+			}
+		case field == entryValue && wire == wireBytes:
+			if value, err = decodeValue(&r); err != nil {
+				return "", nil, err
+			}
+		default:
+			if err := r.skip(wire); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	return key, value, nil
+}
+
+func decodeSDCEntry(parent *wireReader) (string, string, error) {
+	payload, err := parent.bytes()
+	if err != nil {
+		return "", "", err
+	}
+	var key, value string
+	r := wireReader{data: payload}
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return "", "", err
+		}
+		switch {
+		case field == entryKey && wire == wireBytes:
+			key, err = r.string()
+		case field == entryValue && wire == wireBytes:
+			value, err = r.string()
+		default:
+			err = r.skip(wire)
+		}
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return key, value, nil
+}
+
+func decodeFrame(parent *wireReader) (stacktrace, error) {
+	payload, err := parent.bytes()
+	if err != nil {
+		return stacktrace{}, err
+	}
+	var frame stacktrace
+	r := wireReader{data: payload}
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return stacktrace{}, err
+		}
+		switch {
+		case field == frameFunction && wire == wireBytes:
+			frame.Function, err = r.string()
+		case field == frameFile && wire == wireBytes:
+			frame.File, err = r.string()
+		case field == frameLine && wire == wireVarint:
+			var line uint64
+			line, err = r.uvarint()
+			frame.Line = int(line)
+		default:
+			err = r.skip(wire)
+		}
+		if err != nil {
+			return stacktrace{}, err
+		}
+	}
+	return frame, nil
+}
+
+func decodeValue(parent *wireReader) (any, error) {
+	payload, err := parent.bytes()
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	r := wireReader{data: payload}
+	for !r.done() {
+		field, wire, err := r.tag()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == valueString && wire == wireBytes:
+			value, err = r.string()
+		case field == valueInt && wire == wireVarint:
+			var v uint64
+			v, err = r.uvarint()
+			value = unzigzag(v)
+		case field == valueUint && wire == wireVarint:
+			value, err = r.uvarint()
+		case field == valueFloat && wire == wireFixed64:
+			var v uint64
+			v, err = r.fixed64()
+			value = math.Float64frombits(v)
+		case field == valueBool && wire == wireVarint:
+			var v uint64
+			v, err = r.uvarint()
+			value = v != 0
+		case field == valueBytes && wire == wireBytes:
+			var v []byte
+			v, err = r.bytes()
+			value = append([]byte{}, v...)
+		case field == valueNil && wire == wireVarint:
+			_, err = r.uvarint()
+			value = nil
+		case field == valueTime && wire == wireBytes:
+			var v []byte
+			if v, err = r.bytes(); err == nil {
+				var t time.Time
+				if t.UnmarshalText(v) != nil {
+					return nil, fmt.Errorf("%w: invalid time value", ErrBinaryInvalid)
+				}
+				value = t
+			}
+		case field == valueDuration && wire == wireVarint:
+			var v uint64
+			v, err = r.uvarint()
+			value = time.Duration(unzigzag(v))
+		default:
+			err = r.skip(wire)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+func appendTag(out []byte, field, wire int) []byte {
+	return binary.AppendUvarint(out, uint64(field)<<3|uint64(wire))
+}
+
+func appendVarintField(out []byte, field int, v uint64) []byte {
+	return binary.AppendUvarint(appendTag(out, field, wireVarint), v)
+}
+
+func appendFixed64Field(out []byte, field int, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(appendTag(out, field, wireFixed64), v)
+}
+
+func appendBytesField(out []byte, field int, v []byte) []byte {
+	out = binary.AppendUvarint(appendTag(out, field, wireBytes), uint64(len(v)))
+	return append(out, v...)
+}
+
+func appendStringField(out []byte, field int, v string) []byte {
+	out = binary.AppendUvarint(appendTag(out, field, wireBytes), uint64(len(v)))
+	return append(out, v...)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// wireReader reads fields from an encoded message.
+type wireReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *wireReader) done() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *wireReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: bad varint at offset %d", ErrBinaryInvalid, r.pos)
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *wireReader) tag() (int, int, error) {
+	v, err := r.uvarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	if v>>3 == 0 || v>>3 > math.MaxInt32 {
+		return 0, 0, fmt.Errorf("%w: bad field number", ErrBinaryInvalid)
+	}
+	return int(v >> 3), int(v & 7), nil
+}
+
+func (r *wireReader) fixed64() (uint64, error) {
+	if len(r.data)-r.pos < 8 {
+		return 0, fmt.Errorf("%w: truncated fixed64", ErrBinaryInvalid)
+	}
+	v := binary.LittleEndian.Uint64(r.data[r.pos:])
+	r.pos += 8
+	return v, nil
+}
+
+func (r *wireReader) bytes() ([]byte, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(r.data)-r.pos) {
+		return nil, fmt.Errorf("%w: truncated field", ErrBinaryInvalid)
+	}
+	v := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return v, nil
+}
+
+func (r *wireReader) string() (string, error) {
+	v, err := r.bytes()
+	return string(v), err
+}
+
+func (r *wireReader) skip(wire int) error {
+	var err error
+	switch wire {
+	case wireVarint:
+		_, err = r.uvarint()
+	case wireFixed64:
+		_, err = r.fixed64()
+	case wireBytes:
+		_, err = r.bytes()
+	default:
+		err = fmt.Errorf("%w: unknown wire type %d", ErrBinaryInvalid, wire)
+	}
+	return err
+}
@@ -0,0 +1,275 @@
+package flooerr
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func binaryTestError() error {
+	root := errors.New("connection reset")
+	inner := Message("query failed").
+		WithCode("DB_ERR").
+		WithContext("string", "users").
+		WithContext("int", 42).
+		WithContext("uint", uint8(7)).
+		WithContext("float", 1.5).
+		WithContext("bool", true).
+		WithContext("bytes", []byte{1, 2}).
+		WithContext("nil", nil).
+		WithContext("time", time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)).
+		WithContext("duration", -time.Second).
+		WithContext("other", struct{ A int }{A: 1}).
+		WithSDC("db", "primary").
+		Error(root, "query failed")
+	return Message("load failed").WithCode("LOAD_ERR").Error(fmt.Errorf("repo: %w", inner), "load failed")
+}
+
+func TestMarshalBinary_RoundTrip(t *testing.T) {
+	original := binaryTestError()
+	data, err := MarshalBinary(original)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded, err := UnmarshalBinary(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if decoded.Error() != original.Error() {
+		t.Errorf("Expected '%s', got '%s'", original.Error(), decoded.Error())
+	}
+
+	originalChain := ParseChain(original)
+	decodedChain := ParseChain(decoded)
+	if len(decodedChain) != len(originalChain) {
+		t.Fatalf("Expected %d layers, got %d", len(originalChain), len(decodedChain))
+	}
+
+	if decodedChain[1].IsFlooErr || decodedChain[3].IsFlooErr {
+		t.Error("Expected foreign layers to stay foreign")
+	}
+
+	expectedContext := map[string]any{
+		"string":   "users",
+		"int":      int64(42),
+		"uint":     uint64(7),
+		"float":    1.5,
+		"bool":     true,
+		"bytes":    []byte{1, 2},
+		"nil":      nil,
+		"time":     time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC),
+		"duration": -time.Second,
+		"other":    "{1}",
+	}
+	if !reflect.DeepEqual(decodedChain[2].Context, expectedContext) {
+		t.Errorf("Unexpected context %#v", decodedChain[2].Context)
+	}
+
+	if decodedChain[2].SDC["db"] != "primary" || decodedChain[2].Code != "DB_ERR" {
+		t.Errorf("Unexpected layer %+v", decodedChain[2])
+	}
+
+	if !reflect.DeepEqual(decodedChain[0].StackTrace, originalChain[0].StackTrace) {
+		t.Error("Expected symbolized frames to round trip")
+	}
+}
+
+func TestMarshalBinary_Method(t *testing.T) {
+	var marshaler encoding.BinaryMarshaler = Error("test").(*err)
+	data, err := marshaler.MarshalBinary()
+	if err != nil || !bytes.HasPrefix(data, []byte(binaryMagic)) {
+		t.Errorf("Unexpected result %v %v", data, err)
+	}
+}
+
+func TestMarshalBinary_Sentinel(t *testing.T) {
+	sentinel := Sentinel("NOT_FOUND", "not found")
+	data, err := MarshalBinary(Code("NOT_FOUND").Error(nil, "missing"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded, _ := UnmarshalBinary(data)
+	if !errors.Is(decoded, sentinel) {
+		t.Error("Expected decoded error to match sentinel by code")
+	}
+
+	data, _ = MarshalBinary(sentinel)
+	decoded, _ = UnmarshalBinary(data)
+	if !errors.Is(Code("NOT_FOUND").Error(nil, "x"), decoded) {
+		t.Error("Expected decoded sentinel to keep its sentinel flag")
+	}
+}
+
+func TestMarshalBinary_RawFrames(t *testing.T) {
+	original := Message("test").Error(nil, "test")
+	raw, err := BinaryOptions{RawFrames: true}.Marshal(original)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	symbolized, _ := MarshalBinary(original)
+	if len(raw) >= len(symbolized) {
+		t.Errorf("Expected raw frames (%d bytes) to be smaller than symbolized (%d bytes)", len(raw), len(symbolized))
+	}
+
+	decoded, _ := UnmarshalBinary(raw)
+	if !reflect.DeepEqual(GetStackTrace(decoded), GetStackTrace(original)) {
+		t.Error("Expected raw frames to resolve in the same binary")
+	}
+}
+
+func TestMarshalBinary_SizeLimit(t *testing.T) {
+	original := Message("test").Error(nil, "test")
+	full, _ := MarshalBinary(original)
+	noFrames, _ := BinaryOptions{NoFrames: true}.Marshal(original)
+
+	data, err := BinaryOptions{MaxSize: len(full) - 1}.Marshal(original)
+	if err != nil {
+		t.Fatalf("Expected fallback without frames, got %v", err)
+	}
+	if !bytes.Equal(data, noFrames) {
+		t.Error("Expected frames to be dropped to fit the limit")
+	}
+
+	if _, err := (BinaryOptions{MaxSize: len(noFrames) - 1}).Marshal(original); !errors.Is(err, ErrBinaryTooLarge) {
+		t.Errorf("Expected ErrBinaryTooLarge, got %v", err)
+	}
+
+	if _, err := (BinaryOptions{MaxSize: len(full) - 1}).Unmarshal(full); !errors.Is(err, ErrBinaryTooLarge) {
+		t.Errorf("Expected ErrBinaryTooLarge when decoding, got %v", err)
+	}
+}
+
+func TestUnmarshalBinary_LayerLimit(t *testing.T) {
+	err := Error("root")
+	for i := 0; i < 5; i++ {
+		err = Wrap(err, "layer")
+	}
+	data, _ := BinaryOptions{NoFrames: true}.Marshal(err)
+
+	if _, decodeErr := (BinaryOptions{MaxLayers: 5}).Unmarshal(data); !errors.Is(decodeErr, ErrBinaryInvalid) {
+		t.Errorf("Expected ErrBinaryInvalid, got %v", decodeErr)
+	}
+}
+
+func TestUnmarshalBinary_UnknownFields(t *testing.T) {
+	layer := appendStringField(nil, layerCode, "CODE")
+	layer = appendVarintField(layer, 100, 7)
+	layer = appendFixed64Field(layer, 101, 9)
+	layer = appendStringField(layer, 102, "future")
+	layer = appendStringField(layer, layerErrMessage, "message")
+
+	data := append([]byte(binaryMagic), binaryVersion)
+	data = appendStringField(data, 50, "top-level extension")
+	data = appendBytesField(data, fieldLayer, layer)
+
+	decoded, err := UnmarshalBinary(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if GetCodeString(decoded) != "CODE" || decoded.Error() != "message" {
+		t.Errorf("Unexpected decoded error %v", decoded)
+	}
+}
+
+func TestUnmarshalBinary_Invalid(t *testing.T) {
+	valid, _ := MarshalBinary(Error("test"))
+	tests := map[string][]byte{
+		"empty":     nil,
+		"magic":     []byte("XXX\x01"),
+		"version":   []byte("FLE\x02"),
+		"no layers": []byte("FLE\x01"),
+		"truncated": valid[:len(valid)-1],
+		"wire type": append([]byte("FLE\x01"), 0x0f),
+	}
+
+	for name, data := range tests {
+		if _, err := UnmarshalBinary(data); !errors.Is(err, ErrBinaryInvalid) {
+			t.Errorf("%s: expected ErrBinaryInvalid, got %v", name, err)
+		}
+	}
+}
+
+func TestUnmarshalBinary_ForeignRoot(t *testing.T) {
+	original := fmt.Errorf("handler: %w", Code("NOT_FOUND").Error(nil, "missing"))
+	data, _ := MarshalBinary(original)
+
+	decoded, err := UnmarshalBinary(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if decoded.Error() != original.Error() {
+		t.Errorf("Expected the foreign layer on top, got %q", decoded.Error())
+	}
+	if _, ok := decoded.(FlooErr); ok {
+		t.Error("Expected the foreign layer not to be a FlooErr")
+	}
+	if chain := ParseChain(decoded); len(chain) != 2 || chain[0].IsFlooErr || !chain[1].IsFlooErr {
+		t.Errorf("Expected the foreign layer to stay foreign, got %+v", chain)
+	}
+
+	var flooErr FlooErr
+	if !errors.As(decoded, &flooErr) || flooErr.Code() != "NOT_FOUND" {
+		t.Errorf("Expected errors.As to find the coded layer, got %v", flooErr)
+	}
+}
+
+func TestMarshalBinary_Nil(t *testing.T) {
+	if _, err := MarshalBinary(nil); err == nil {
+		t.Error("Expected error for nil")
+	}
+}
+
+func TestZigzag(t *testing.T) {
+	for _, v := range []int64{0, -1, 1, -1 << 63, 1<<63 - 1} {
+		if unzigzag(zigzag(v)) != v {
+			t.Errorf("zigzag round trip failed for %d", v)
+		}
+	}
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	for _, err := range []error{binaryTestError(), Error("test"), Sentinel("X", "x")} {
+		data, _ := MarshalBinary(err)
+		f.Add(data)
+		raw, _ := BinaryOptions{RawFrames: true}.Marshal(err)
+		f.Add(raw)
+	}
+	f.Add([]byte("FLE\x01"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, err := BinaryOptions{RawFrames: true}.Unmarshal(data)
+		if err != nil {
+			if !errors.Is(err, ErrBinaryInvalid) && !errors.Is(err, ErrBinaryTooLarge) {
+				t.Fatalf("Unexpected error type: %v", err)
+			}
+			return
+		}
+
+		// Decoded errors must be usable and re-encodable.
+		_ = decoded.Error()
+		_ = fmt.Sprintf("%+v", decoded)
+		reencoded, err := BinaryOptions{NoFrames: true}.Marshal(decoded)
+		if err != nil && !errors.Is(err, ErrBinaryTooLarge) {
+			t.Fatalf("Re-encoding failed: %v", err)
+		}
+		if err == nil {
+			again, err := UnmarshalBinary(reencoded)
+			if err != nil {
+				t.Fatalf("Decoding re-encoded data failed: %v", err)
+			}
+			if again.Error() != decoded.Error() && !strings.Contains(decoded.Error(), "caused by") {
+				t.Fatalf("Round trip changed error: %q != %q", again.Error(), decoded.Error())
+			}
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package flooerr
+
+import "errors"
+
+// ParseChain describes every layer of the error chain, outermost first.
+// Layers that are not FlooErrs are reported with IsFlooErr = false, their
+// error message and their cause.
+func ParseChain(err error) []ErrorInfo {
+	chain := UnwrapChain(err)
+	if len(chain) == 0 {
+		return nil
+	}
+
+	infos := make([]ErrorInfo, 0, len(chain))
+	for _, layer := range chain {
+		if flooErr, ok := asLayer(layer); ok {
+			infos = append(infos, infoOf(flooErr))
+			continue
+		}
+		infos = append(infos, ErrorInfo{
+			ErrorMsg:  layer.Error(),
+			Text:      layer.Error(),
+			Cause:     errors.Unwrap(layer),
+			IsFlooErr: false,
+		})
+	}
+	return infos
+}
+
+// Precedence decides which layer wins when merging values from the chain.
+type Precedence int
+
+const (
+	// OutermostWins keeps the value closest to the top of the chain.
+	OutermostWins Precedence = iota
+	// InnermostWins keeps the value closest to the root cause.
+	InnermostWins
+)
+
+// Conflict describes a key set by more than one layer. Layers are indexes
+// into UnwrapChain(err).
+type Conflict struct {
+	Key          string
+	Kept         any
+	KeptLayer    int
+	Dropped      any
+	DroppedLayer int
+}
+
+type mergeConfig struct {
+	precedence Precedence
+	onConflict func(Conflict)
+}
+
+// MergeOption configures MergedContext and MergedSDC.
+type MergeOption func(*mergeConfig)
+
+// WithPrecedence sets which layer wins on conflicting keys. The default is
+// OutermostWins.
+func WithPrecedence(precedence Precedence) MergeOption {
+	return func(config *mergeConfig) {
+		config.precedence = precedence
+	}
+}
+
+// OnConflict registers fn to be called for every value dropped because
+// another layer set the same key. Keys set to equal values in several
+// layers are not reported.
+func OnConflict(fn func(Conflict)) MergeOption {
+	return func(config *mergeConfig) {
+		config.onConflict = fn
+	}
+}
+
+// MergedContext merges the context of every FlooErr in the chain.
+// Returns nil if the chain contains no FlooErr.
+func MergedContext(err error, opts ...MergeOption) map[string]any {
+	return mergeChain(err, opts, func(flooErr FlooErr) map[string]any {
+		return flooErr.Context()
+	})
+}
+
+// MergedSDC merges the SDC of every FlooErr in the chain.
+// Returns nil if the chain contains no FlooErr.
+func MergedSDC(err error, opts ...MergeOption) map[string]string {
+	return mergeChain(err, opts, func(flooErr FlooErr) map[string]string {
+		return flooErr.SDC()
+	})
+}
+
+func mergeChain[V any](err error, opts []MergeOption, values func(FlooErr) map[string]V) map[string]V {
+	config := mergeConfig{precedence: OutermostWins}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	chain := UnwrapChain(err)
+	layers := make([]int, 0, len(chain))
+	for i, layer := range chain {
+		if _, ok := asLayer(layer); ok {
+			layers = append(layers, i)
+		}
+	}
+	if len(layers) == 0 {
+		return nil
+	}
+
+	// Visit the winning layer first so later layers only fill gaps.
+	if config.precedence == InnermostWins {
+		for i, j := 0, len(layers)-1; i < j; i, j = i+1, j-1 {
+			layers[i], layers[j] = layers[j], layers[i]
+		}
+	}
+
+	merged := make(map[string]V)
+	source := make(map[string]int)
+	for _, index := range layers {
+		flooErr, _ := asLayer(chain[index])
+		for key, value := range values(flooErr) {
+			kept, exists := merged[key]
+			if !exists {
+				merged[key] = value
+				source[key] = index
+				continue
+			}
+			if config.onConflict != nil && !equalValues(kept, value) {
+				config.onConflict(Conflict{
+					Key:          key,
+					Kept:         kept,
+					KeptLayer:    source[key],
+					Dropped:      value,
+					DroppedLayer: index,
+				})
+			}
+		}
+	}
+	return merged
+}
+
+// equalValues compares values without panicking on uncomparable types,
+// which are always treated as different.
+func equalValues(a, b any) (equal bool) {
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return any(a) == any(b)
+}
@@ -0,0 +1,142 @@
+package flooerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func testChain() error {
+	root := errors.New("sql: no rows")
+	repo := Message("find failed").
+		WithCode("NOT_FOUND").
+		WithContext("table", "users").
+		WithContext("id", 42).
+		WithSDC("db", "primary").
+		Error(root, "find failed")
+	wrapped := fmt.Errorf("service: %w", repo)
+	return Message("get user failed").
+		WithCode("USER_ERR").
+		WithContext("id", "42").
+		WithSDC("request_id", "req_1").
+		WithSDC("db", "replica").
+		Error(wrapped, "get user failed")
+}
+
+func TestParseChain(t *testing.T) {
+	infos := ParseChain(testChain())
+
+	if len(infos) != 4 {
+		t.Fatalf("Expected 4 layers, got %d", len(infos))
+	}
+
+	if infos[0].Code != "USER_ERR" || !infos[0].IsFlooErr {
+		t.Errorf("Unexpected outer layer %+v", infos[0])
+	}
+
+	if infos[1].IsFlooErr {
+		t.Error("Expected fmt.Errorf layer not to be a FlooErr")
+	}
+
+	if infos[2].Code != "NOT_FOUND" || infos[2].Context["table"] != "users" {
+		t.Errorf("Unexpected repository layer %+v", infos[2])
+	}
+
+	if infos[3].ErrorMsg != "sql: no rows" || infos[3].IsFlooErr {
+		t.Errorf("Unexpected root layer %+v", infos[3])
+	}
+}
+
+func TestParseChain_Text(t *testing.T) {
+	infos := ParseChain(testChain())
+
+	expected := []string{"get user failed", infos[1].ErrorMsg, "find failed", "sql: no rows"}
+	for i, info := range infos {
+		if info.Text != expected[i] {
+			t.Errorf("Layer %d: expected text '%s', got '%s'", i, expected[i], info.Text)
+		}
+	}
+}
+
+func TestParseChain_Nil(t *testing.T) {
+	if infos := ParseChain(nil); infos != nil {
+		t.Errorf("Expected nil, got %v", infos)
+	}
+}
+
+func TestUnwrapChain_ForeignWrapper(t *testing.T) {
+	inner := Message("inner").Error(errors.New("root"), "inner")
+	chain := UnwrapChain(fmt.Errorf("outer: %w", inner))
+
+	if len(chain) != 3 {
+		t.Fatalf("Expected chain length 3, got %d", len(chain))
+	}
+
+	if chain[1] != inner {
+		t.Error("Expected FlooErr wrapped by fmt.Errorf to be its own layer")
+	}
+}
+
+func TestMergedContext_OutermostWins(t *testing.T) {
+	var conflicts []Conflict
+	merged := MergedContext(testChain(), OnConflict(func(c Conflict) {
+		conflicts = append(conflicts, c)
+	}))
+
+	if merged["id"] != "42" {
+		t.Errorf("Expected outer id '42', got %#v", merged["id"])
+	}
+
+	if merged["table"] != "users" {
+		t.Errorf("Expected inner table 'users', got %v", merged["table"])
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 conflict, got %d", len(conflicts))
+	}
+
+	c := conflicts[0]
+	if c.Key != "id" || c.KeptLayer != 0 || c.DroppedLayer != 2 || c.Dropped != 42 {
+		t.Errorf("Unexpected conflict %+v", c)
+	}
+}
+
+func TestMergedContext_InnermostWins(t *testing.T) {
+	merged := MergedContext(testChain(), WithPrecedence(InnermostWins))
+
+	if merged["id"] != 42 {
+		t.Errorf("Expected inner id 42, got %#v", merged["id"])
+	}
+}
+
+func TestMergedSDC(t *testing.T) {
+	merged := MergedSDC(testChain())
+	if merged["db"] != "replica" || merged["request_id"] != "req_1" {
+		t.Errorf("Unexpected merged SDC %v", merged)
+	}
+
+	merged = MergedSDC(testChain(), WithPrecedence(InnermostWins))
+	if merged["db"] != "primary" {
+		t.Errorf("Expected inner db 'primary', got '%s'", merged["db"])
+	}
+}
+
+func TestMergedContext_EqualValuesNoConflict(t *testing.T) {
+	inner := Message("inner").WithContext("k", "v").WithContext("list", []int{1}).Error(nil, "inner")
+	outer := Message("outer").WithContext("k", "v").WithContext("list", []int{1}).Error(inner, "outer")
+
+	var conflicts []Conflict
+	MergedContext(outer, OnConflict(func(c Conflict) {
+		conflicts = append(conflicts, c)
+	}))
+
+	if len(conflicts) != 1 || conflicts[0].Key != "list" {
+		t.Errorf("Expected only the uncomparable key to conflict, got %+v", conflicts)
+	}
+}
+
+func TestMergedContext_NoFlooErr(t *testing.T) {
+	if merged := MergedContext(errors.New("standard")); merged != nil {
+		t.Errorf("Expected nil, got %v", merged)
+	}
+}
@@ -0,0 +1,195 @@
+package flooerr
+
+import (
+	"net/http"
+	"sync"
+)
+
+// HasCodeUnder reports whether any FlooErr in the chain of err has a code
+// under prefix, such as "PAYMENT.CARD.DECLINED" under "PAYMENT.CARD". See
+// ErrCode.Under.
+func HasCodeUnder(err error, prefix string) bool {
+	for flooErr := range FlooErrs(err) {
+		if flooErr.Code().Under(ErrCode(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// CodeTable maps codes to values and resolves a code by its longest
+// registered prefix, so a family of codes can share a default:
+//
+//	statuses := flooerr.NewCodeTable(map[flooerr.ErrCode]int{
+//		"PAYMENT":              402,
+//		"PAYMENT.CARD.EXPIRED": 400,
+//	})
+//	statuses.Lookup("PAYMENT.CARD.DECLINED") // 402, true
+//
+// It is safe for concurrent use.
+type CodeTable[V any] struct {
+	mu      sync.RWMutex
+	entries map[ErrCode]V
+}
+
+// NewCodeTable returns a table holding a copy of entries.
+func NewCodeTable[V any](entries map[ErrCode]V) *CodeTable[V] {
+	t := &CodeTable[V]{entries: make(map[ErrCode]V, len(entries))}
+	for code, value := range entries {
+		t.entries[code] = value
+	}
+	return t
+}
+
+// Set maps code, and the codes under it without an entry of their own, to
+// value.
+func (t *CodeTable[V]) Set(code ErrCode, value V) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries[code] = value
+}
+
+// Delete removes the entry of code.
+func (t *CodeTable[V]) Delete(code ErrCode) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, code)
+}
+
+// Lookup returns the value of code, or of its nearest ancestor with an
+// entry.
+func (t *CodeTable[V]) Lookup(code ErrCode) (V, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for ; code != ""; code = code.Parent() {
+		if value, ok := t.entries[code]; ok {
+			return value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// LookupErr resolves the codes of the chain of err, outermost first, and
+// returns the first value found.
+func (t *CodeTable[V]) LookupErr(err error) (V, bool) {
+	for flooErr := range FlooErrs(err) {
+		if value, ok := t.Lookup(flooErr.Code()); ok {
+			return value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Severity ranks how much attention an error deserves.
+type Severity int
+
+const (
+	SeverityDebug Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// Codes of common failures of the standard library, which the default
+// tables below and flooerr/mapper use.
+const (
+	CodeCanceled         ErrCode = "CANCELED"
+	CodeDeadlineExceeded ErrCode = "DEADLINE_EXCEEDED"
+	CodeNotFound         ErrCode = "NOT_FOUND"
+	CodeAlreadyExists    ErrCode = "ALREADY_EXISTS"
+	CodePermissionDenied ErrCode = "PERMISSION_DENIED"
+	CodeUnexpectedEOF    ErrCode = "UNEXPECTED_EOF"
+	CodeInvalidJSON      ErrCode = "INVALID_JSON"
+	CodeInvalidArgument  ErrCode = "INVALID_ARGUMENT"
+	CodeNetwork          ErrCode = "NETWORK_ERROR"
+)
+
+// Default mapping tables. Register the codes of a service, or of a whole
+// family of codes, with Set.
+var (
+	// HTTPStatusTable maps codes to HTTP response statuses.
+	HTTPStatusTable = NewCodeTable(map[ErrCode]int{
+		CodeNotFound:         http.StatusNotFound,
+		CodeAlreadyExists:    http.StatusConflict,
+		CodePermissionDenied: http.StatusForbidden,
+		CodeInvalidArgument:  http.StatusBadRequest,
+		CodeInvalidJSON:      http.StatusBadRequest,
+		CodeCanceled:         499,
+		CodeDeadlineExceeded: http.StatusGatewayTimeout,
+	})
+	// RetryableTable marks codes whose failures are worth retrying.
+	RetryableTable = NewCodeTable(map[ErrCode]bool{
+		CodeDeadlineExceeded: true,
+		CodeNetwork:          true,
+	})
+	// SeverityTable maps codes to severities.
+	SeverityTable = NewCodeTable(map[ErrCode]Severity{
+		CodeNotFound:         SeverityInfo,
+		CodeAlreadyExists:    SeverityInfo,
+		CodeInvalidArgument:  SeverityInfo,
+		CodeInvalidJSON:      SeverityInfo,
+		CodeCanceled:         SeverityInfo,
+		CodePermissionDenied: SeverityWarning,
+		CodePanic:            SeverityCritical,
+	})
+)
+
+// HTTPStatus returns the HTTP status of err from HTTPStatusTable, or 500
+// when no code of the chain resolves. It returns 200 for a nil err.
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if status, ok := HTTPStatusTable.LookupErr(err); ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// KeyRetryable marks a layer as worth retrying or not, for packages that
+// decide per error rather than per code, such as flooerr/httpclient.
+var KeyRetryable = NewKey[bool]("retryable")
+
+// IsRetryable reports whether err is worth retrying. The layers of the
+// chain are searched outermost first; the first one holding KeyRetryable or
+// a code that resolves in RetryableTable decides. Errors with neither are
+// not retryable.
+func IsRetryable(err error) bool {
+	for flooErr := range FlooErrs(err) {
+		if retryable, ok := convertValue[bool](flooErr.Context()[KeyRetryable.name]); ok {
+			return retryable
+		}
+		if retryable, ok := RetryableTable.Lookup(flooErr.Code()); ok {
+			return retryable
+		}
+	}
+	return false
+}
+
+// GetSeverity returns the severity of err from SeverityTable, or
+// SeverityError when no code of the chain resolves.
+func GetSeverity(err error) Severity {
+	if severity, ok := SeverityTable.LookupErr(err); ok {
+		return severity
+	}
+	return SeverityError
+}
@@ -0,0 +1,151 @@
+package flooerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestHasCodeUnder(t *testing.T) {
+	declined := Code("PAYMENT.CARD.DECLINED").Error(nil, "card declined")
+	err := fmt.Errorf("checkout: %w", Code("CHECKOUT").Wrap(declined, "checkout failed"))
+
+	for prefix, expected := range map[string]bool{
+		"PAYMENT.CARD.DECLINED": true,
+		"PAYMENT.CARD":          true,
+		"PAYMENT":               true,
+		"CHECKOUT":              true,
+		"PAYMENT.CARDS":         false,
+		"PAYMENT.CARD.EXPIRED":  false,
+	} {
+		if actual := HasCodeUnder(err, prefix); actual != expected {
+			t.Errorf("HasCodeUnder(%q) = %v, expected %v", prefix, actual, expected)
+		}
+	}
+
+	if HasCodeUnder(errors.New("plain"), "") || HasCodeUnder(nil, "PAYMENT") {
+		t.Error("Expected no match without FlooErrs")
+	}
+}
+
+func TestCodeTable_LongestPrefix(t *testing.T) {
+	entries := map[ErrCode]int{
+		"PAYMENT":              402,
+		"PAYMENT.CARD.EXPIRED": 400,
+	}
+	table := NewCodeTable(entries)
+	entries["OTHER"] = 1
+
+	tests := map[ErrCode]int{
+		"PAYMENT":                402,
+		"PAYMENT.CARD":           402,
+		"PAYMENT.CARD.DECLINED":  402,
+		"PAYMENT.CARD.EXPIRED":   400,
+		"PAYMENT.CARD.EXPIRED.X": 400,
+	}
+	for code, expected := range tests {
+		if actual, ok := table.Lookup(code); !ok || actual != expected {
+			t.Errorf("Lookup(%q) = %d, %v; expected %d", code, actual, ok, expected)
+		}
+	}
+	for _, code := range []ErrCode{"PAYMENTS", "OTHER", ""} {
+		if _, ok := table.Lookup(code); ok {
+			t.Errorf("Expected no entry for %q", code)
+		}
+	}
+
+	table.Set("PAYMENT.CARD", 422)
+	if actual, _ := table.Lookup("PAYMENT.CARD.DECLINED"); actual != 422 {
+		t.Errorf("Expected the new family entry, got %d", actual)
+	}
+	table.Delete("PAYMENT.CARD")
+	if actual, _ := table.Lookup("PAYMENT.CARD.DECLINED"); actual != 402 {
+		t.Errorf("Expected the root entry after Delete, got %d", actual)
+	}
+}
+
+func TestCodeTable_LookupErr(t *testing.T) {
+	table := NewCodeTable(map[ErrCode]string{"DB": "db", "API.USER": "user"})
+
+	inner := Code("DB.QUERY.TIMEOUT").Error(nil, "query timed out")
+	if value, _ := table.LookupErr(Code("API.USER.GET").Wrap(inner, "get user")); value != "user" {
+		t.Errorf("Expected the outermost resolving code to win, got %q", value)
+	}
+	if value, _ := table.LookupErr(Code("UNKNOWN").Wrap(inner, "get user")); value != "db" {
+		t.Errorf("Expected inner codes to be tried, got %q", value)
+	}
+	if _, ok := table.LookupErr(errors.New("plain")); ok {
+		t.Error("Expected no value for a plain error")
+	}
+}
+
+func TestCodeTable_Concurrent(t *testing.T) {
+	table := NewCodeTable[int](nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				table.Set("A.B", j)
+				table.Lookup("A.B.C")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDefaultTables(t *testing.T) {
+	notFound := Code(CodeNotFound).Error(nil, "missing")
+	if status := HTTPStatus(notFound); status != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", status)
+	}
+	if status := HTTPStatus(errors.New("plain")); status != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for unknown errors, got %d", status)
+	}
+	if status := HTTPStatus(nil); status != http.StatusOK {
+		t.Errorf("Expected 200 for nil, got %d", status)
+	}
+
+	HTTPStatusTable.Set("PAYMENT", http.StatusPaymentRequired)
+	RetryableTable.Set("PAYMENT.GATEWAY", true)
+	SeverityTable.Set("PAYMENT.CARD", SeverityWarning)
+	defer func() {
+		HTTPStatusTable.Delete("PAYMENT")
+		RetryableTable.Delete("PAYMENT.GATEWAY")
+		SeverityTable.Delete("PAYMENT.CARD")
+	}()
+
+	declined := Code("PAYMENT.CARD.DECLINED").Error(nil, "declined")
+	gateway := Code("PAYMENT.GATEWAY.UNAVAILABLE").Error(nil, "gateway down")
+	if HTTPStatus(declined) != http.StatusPaymentRequired || HTTPStatus(gateway) != http.StatusPaymentRequired {
+		t.Error("Expected the PAYMENT family to share its status")
+	}
+	if IsRetryable(declined) || !IsRetryable(gateway) {
+		t.Error("Expected only the gateway family to be retryable")
+	}
+	marked := With(Code("PAYMENT.GATEWAY.UNAVAILABLE"), KeyRetryable, false).Error(nil, "gateway gone")
+	if IsRetryable(marked) || !IsRetryable(With(Message("timeout"), KeyRetryable, true).Error(nil, "")) {
+		t.Error("Expected KeyRetryable to decide over the code")
+	}
+	if !IsRetryable(Wrap(With(Message("timeout"), KeyRetryable, true).Error(nil, ""), "wrapped")) {
+		t.Error("Expected the mark of a wrapped layer to count")
+	}
+	if GetSeverity(declined) != SeverityWarning || GetSeverity(gateway) != SeverityError {
+		t.Errorf("Unexpected severities %s and %s", GetSeverity(declined), GetSeverity(gateway))
+	}
+	if GetSeverity(notFound) != SeverityInfo || GetSeverity(Code(CodePanic).Error(nil, "boom")) != SeverityCritical {
+		t.Error("Unexpected default severities")
+	}
+}
+
+func TestPublicMessage_FamilyFallback(t *testing.T) {
+	SetPublicFallback("PAYMENT", "Your payment could not be processed.")
+	defer SetPublicFallback("PAYMENT", "")
+
+	if message := PublicMessage(Code("PAYMENT.CARD.DECLINED").Error(nil, "issuer said no")); message != "Your payment could not be processed." {
+		t.Errorf("Expected the family fallback, got %q", message)
+	}
+}
@@ -0,0 +1,223 @@
+// Package conc runs tasks concurrently and collects their failures as
+// FlooErrs.
+//
+// Unlike errgroup, a Group keeps every failure rather than the first one,
+// and tags each with the name and index of the task that returned it:
+//
+//	group := conc.New(ctx, conc.WithLimit(8))
+//	for _, id := range ids {
+//		group.Go("fetch "+id, func(ctx context.Context) error {
+//			return fetch(ctx, id)
+//		})
+//	}
+//	if err := group.Wait(); err != nil {
+//		for _, failure := range conc.Failures(err) {
+//			name, _ := flooerr.Lookup(failure, conc.KeyTask)
+//			...
+//		}
+//	}
+package conc
+
+import (
+	"context"
+	"core-common-go/flooerr"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// Context keys of the errors returned by this package.
+var (
+	// KeyTask and KeyTaskIndex identify the task of a failure. The index
+	// counts calls to Go from 0.
+	KeyTask      = flooerr.NewKey[string]("task")
+	KeyTaskIndex = flooerr.NewKey[int]("task_index")
+	// KeyFailedTasks and KeyFailedIndexes list the failed tasks on the
+	// aggregate returned by Wait.
+	KeyFailedTasks   = flooerr.NewKey[[]string]("failed_tasks")
+	KeyFailedIndexes = flooerr.NewKey[[]int]("failed_indexes")
+)
+
+// Mode selects how a Group reacts to a failure.
+type Mode int
+
+const (
+	// CollectAll runs every task to completion. It is the default.
+	CollectAll Mode = iota
+	// FailFast cancels the context of the group on the first failure.
+	// Tasks that have not started are skipped, and context.Canceled
+	// errors returned after the failure are not reported, so the
+	// aggregate holds only the failures that caused the cancellation.
+	FailFast
+)
+
+// Option configures a Group.
+type Option func(*Group)
+
+// WithLimit runs at most n tasks at a time. A limit below 1 means no limit,
+// which is the default.
+func WithLimit(n int) Option {
+	return func(g *Group) {
+		if n > 0 {
+			g.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithMode sets the failure mode of the group.
+func WithMode(mode Mode) Option {
+	return func(g *Group) {
+		g.mode = mode
+	}
+}
+
+// Group runs tasks on their own goroutines and aggregates their failures.
+// A Group must be created with New and not reused after Wait.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	slots  chan struct{}
+	mode   Mode
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	tasks    int
+	failures []failure
+}
+
+type failure struct {
+	index int
+	name  string
+	err   error
+}
+
+// New returns a Group whose tasks run with a context derived from ctx. The
+// context is canceled when Wait returns, and on the first failure in
+// FailFast mode.
+func New(ctx context.Context, opts ...Option) *Group {
+	g := &Group{}
+	g.ctx, g.cancel = context.WithCancelCause(ctx)
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Go runs task on a new goroutine once a slot is free. It never blocks.
+// An empty name is replaced by the index of the task. A panic in task is
+// recovered and reported as a failure with flooerr.CodePanic.
+//
+// A task that has not started when the context of the group is done is not
+// run; it is reported as failed with the cause of the cancellation, unless
+// the group was canceled by FailFast.
+func (g *Group) Go(name string, task func(ctx context.Context) error) {
+	g.mu.Lock()
+	index := g.tasks
+	g.tasks++
+	g.mu.Unlock()
+	if name == "" {
+		name = strconv.Itoa(index)
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		if g.slots != nil {
+			select {
+			case g.slots <- struct{}{}:
+				defer func() { <-g.slots }()
+			case <-g.ctx.Done():
+			}
+		}
+		if g.ctx.Err() != nil {
+			g.skip(index, name)
+			return
+		}
+
+		err := flooerr.Try(func() (struct{}, error) {
+			return struct{}{}, task(g.ctx)
+		}).Err()
+		if err != nil {
+			g.fail(index, name, err)
+		}
+	}()
+}
+
+// skip records a task that was not run because the context of the group
+// was done, unless the group failed fast.
+func (g *Group) skip(index int, name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.mode == FailFast && len(g.failures) > 0 {
+		return
+	}
+	g.failures = append(g.failures, failure{index: index, name: name, err: context.Cause(g.ctx)})
+}
+
+// fail records the failure of a task, unless the group already failed fast
+// and err is a consequence of that.
+func (g *Group) fail(index int, name string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.mode == FailFast && len(g.failures) > 0 && errors.Is(err, context.Canceled) {
+		return
+	}
+	g.failures = append(g.failures, failure{index: index, name: name, err: err})
+	if g.mode == FailFast {
+		g.cancel(err)
+	}
+}
+
+// Wait waits for every task and returns an error aggregating their
+// failures, or nil when all of them succeeded. The aggregate is a FlooErr
+// with flooerr.CodeMultiple whose cause joins the failures in task order,
+// so errors.Is and errors.As see each of them. Each failure is wrapped in a
+// layer carrying KeyTask and KeyTaskIndex that inherits the code of the
+// task error. Use Failures to list them.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.failures) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(g.failures, func(a, b failure) int { return a.index - b.index })
+	errs := make([]error, len(g.failures))
+	names := make([]string, len(g.failures))
+	indexes := make([]int, len(g.failures))
+	for i, f := range g.failures {
+		message := fmt.Sprintf("task %s failed", f.name)
+		props := flooerr.Message(message).WithInheritCode(true).WithStackTrace(false)
+		flooerr.With(props, KeyTask, f.name)
+		errs[i] = flooerr.With(props, KeyTaskIndex, f.index).Build(f.err, message)
+		names[i] = f.name
+		indexes[i] = f.index
+	}
+
+	message := fmt.Sprintf("%d of %d tasks failed", len(errs), g.tasks)
+	props := flooerr.Message(message).WithCode(flooerr.CodeMultiple.String()).WithStackTrace(false)
+	flooerr.With(props, KeyFailedTasks, names)
+	return flooerr.With(props, KeyFailedIndexes, indexes).Build(errors.Join(errs...), message)
+}
+
+// Failures returns the tagged task failures aggregated in err by Wait, in
+// task order, or nil if err holds no such aggregate.
+func Failures(err error) []error {
+	for flooErr := range flooerr.FlooErrs(err) {
+		if _, ok := flooErr.Context()[KeyFailedTasks.KeyName()]; !ok {
+			continue
+		}
+		if joined, ok := errors.Unwrap(flooErr).(interface{ Unwrap() []error }); ok {
+			return joined.Unwrap()
+		}
+	}
+	return nil
+}
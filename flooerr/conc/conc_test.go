@@ -0,0 +1,173 @@
+package conc
+
+import (
+	"context"
+	"core-common-go/flooerr"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup_Success(t *testing.T) {
+	group := New(context.Background())
+	var ran atomic.Int32
+	for range 5 {
+		group.Go("", func(context.Context) error {
+			ran.Add(1)
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if ran.Load() != 5 {
+		t.Errorf("Expected 5 tasks to run, got %d", ran.Load())
+	}
+}
+
+func TestGroup_CollectAll(t *testing.T) {
+	group := New(context.Background())
+	group.Go("read config", func(context.Context) error {
+		return flooerr.Code("CONFIG").Wrap(io.EOF, "read failed")
+	})
+	group.Go("ok", func(context.Context) error { return nil })
+	group.Go("", func(context.Context) error { return errors.New("boom") })
+
+	err := group.Wait()
+	if flooerr.GetCode(err) != flooerr.CodeMultiple {
+		t.Fatalf("Expected an aggregate, got %v", err)
+	}
+	if flooerr.GetMessage(err) != "2 of 3 tasks failed" {
+		t.Errorf("Unexpected message %q", flooerr.GetMessage(err))
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Error("Expected errors.Is to see every failure")
+	}
+	if names, _ := flooerr.Lookup(err, KeyFailedTasks); len(names) != 2 || names[0] != "read config" || names[1] != "2" {
+		t.Errorf("Unexpected failed tasks %q", names)
+	}
+
+	failures := Failures(err)
+	if len(failures) != 2 {
+		t.Fatalf("Expected 2 failures, got %d", len(failures))
+	}
+	if name, _ := flooerr.Lookup(failures[0], KeyTask); name != "read config" {
+		t.Errorf("Expected the task name, got %q", name)
+	}
+	if index, _ := flooerr.Lookup(failures[1], KeyTaskIndex); index != 2 {
+		t.Errorf("Expected the task index, got %d", index)
+	}
+	if code := flooerr.GetCode(failures[0]); code != "CONFIG" {
+		t.Errorf("Expected the failure to inherit the task code, got %s", code)
+	}
+	if failures[1].Error() != "task 2 failed; caused by: boom" {
+		t.Errorf("Unexpected failure text %q", failures[1].Error())
+	}
+}
+
+func TestGroup_Panic(t *testing.T) {
+	group := New(context.Background())
+	group.Go("explode", func(context.Context) error { panic("kaboom") })
+
+	failures := Failures(group.Wait())
+	if len(failures) != 1 || flooerr.GetCode(failures[0]) != flooerr.CodePanic {
+		t.Fatalf("Expected a recovered panic, got %v", failures)
+	}
+	if name, _ := flooerr.Lookup(failures[0], KeyTask); name != "explode" {
+		t.Errorf("Expected the panic to be tagged with the task, got %q", name)
+	}
+}
+
+func TestGroup_Limit(t *testing.T) {
+	group := New(context.Background(), WithLimit(2))
+	var running, peak atomic.Int32
+	for range 10 {
+		group.Go("", func(context.Context) error {
+			current := running.Add(1)
+			for {
+				previous := peak.Load()
+				if current <= previous || peak.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 concurrent tasks, got %d", peak.Load())
+	}
+}
+
+func TestGroup_FailFast(t *testing.T) {
+	group := New(context.Background(), WithMode(FailFast))
+	group.Go("fail", func(context.Context) error { return errors.New("first") })
+	group.Go("canceled", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	failures := Failures(group.Wait())
+	if len(failures) != 1 || failures[0].Error() != "task fail failed; caused by: first" {
+		t.Errorf("Expected only the first failure, got %v", failures)
+	}
+}
+
+func TestGroup_FailFastSkipsQueued(t *testing.T) {
+	group := New(context.Background(), WithMode(FailFast), WithLimit(1))
+	started, release := make(chan struct{}), make(chan struct{})
+	group.Go("fail", func(context.Context) error {
+		close(started)
+		<-release
+		return errors.New("first")
+	})
+	<-started
+	group.Go("queued", func(context.Context) error {
+		t.Error("Expected queued tasks to be skipped after a failure")
+		return nil
+	})
+	close(release)
+
+	if failures := Failures(group.Wait()); len(failures) != 1 {
+		t.Errorf("Expected the skipped task not to be reported, got %v", failures)
+	}
+}
+
+func TestGroup_ParentCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	group := New(ctx, WithLimit(1))
+	started := make(chan struct{})
+	group.Go("running", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	})
+	<-started
+	group.Go("queued", func(context.Context) error {
+		t.Error("Expected queued tasks not to run")
+		return nil
+	})
+	cancel()
+
+	failures := Failures(group.Wait())
+	if len(failures) != 1 || !errors.Is(failures[0], context.Canceled) {
+		t.Fatalf("Expected the queued task to fail with the cancellation, got %v", failures)
+	}
+	if name, _ := flooerr.Lookup(failures[0], KeyTask); name != "queued" {
+		t.Errorf("Unexpected task %q", name)
+	}
+}
+
+func TestFailures_Foreign(t *testing.T) {
+	if Failures(errors.Join(io.EOF)) != nil || Failures(nil) != nil {
+		t.Error("Expected no failures outside a group aggregate")
+	}
+}
@@ -0,0 +1,304 @@
+// Package devpage renders failed requests as an HTML page for development.
+//
+// The page shows the error chain, codes, context, SDC, the stack with
+// source snippets and the request headers, so it must never be served in
+// production. It is off unless enabled with Options.Enabled or EnvEnable.
+package devpage
+
+import (
+	"core-common-go/flooerr"
+	"core-common-go/flooerr/internal"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"maps"
+	"mime"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// EnvEnable is the environment variable that enables the page when set to
+// "1" or "true".
+const EnvEnable = "FLOOERR_DEV"
+
+// Options configures Handler.
+type Options struct {
+	// Enabled turns the page on regardless of EnvEnable.
+	Enabled bool
+	// Fallback writes the error when the page is not used: when it is not
+	// enabled or for requests that do not accept HTML. It defaults to a JSON
+	// body with status 500.
+	Fallback func(w http.ResponseWriter, r *http.Request, err error)
+	// Late reports errors returned after the handler started the response,
+	// which can no longer be rendered. Such errors are dropped when it is
+	// nil.
+	Late func(r *http.Request, err error)
+	// ModulePrefix marks in-module frames, as in flooerr.PrettyOptions.
+	ModulePrefix string
+	// SourceLines is the number of lines shown around in-module frames; 0
+	// means flooerr.DefaultSourceLines.
+	SourceLines int
+}
+
+// Handler adapts an error-returning handler for development. When the
+// handler returns an error, the page is enabled and the request accepts
+// text/html, the error is rendered as a self-contained HTML page. Otherwise
+// the Fallback renders the error, unless the handler already wrote part of
+// the response: then the error goes to Late.
+func Handler(handler func(w http.ResponseWriter, r *http.Request) error, opts Options) http.Handler {
+	if opts.Fallback == nil {
+		opts.Fallback = writeJSONError
+	}
+	if opts.SourceLines <= 0 {
+		opts.SourceLines = flooerr.DefaultSourceLines
+	}
+	enabled := opts.Enabled || envEnabled()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracked := &trackingWriter{ResponseWriter: w}
+		err := handler(tracked, r)
+		if err == nil {
+			return
+		}
+		if tracked.wrote {
+			if opts.Late != nil {
+				opts.Late(r, err)
+			}
+			return
+		}
+		if !enabled || !acceptsHTML(r) {
+			opts.Fallback(w, r, err)
+			return
+		}
+
+		var page strings.Builder
+		if renderErr := pageTemplate.Execute(&page, newPage(err, r, opts)); renderErr != nil {
+			opts.Fallback(w, r, err)
+			return
+		}
+		flooerr.SetIDHeader(w.Header(), err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, page.String())
+	})
+}
+
+// trackingWriter records whether the handler started the response.
+type trackingWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *trackingWriter) Write(data []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func envEnabled() bool {
+	value := strings.ToLower(os.Getenv(EnvEnable))
+	return value == "1" || value == "true"
+}
+
+// acceptsHTML reports whether the Accept header explicitly lists text/html,
+// so API clients sending */* keep getting the fallback.
+func acceptsHTML(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, parseErr := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if parseErr == nil && mediaType == "text/html" && params["q"] != "0" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSONError is the default Options.Fallback. It writes the public view
+// of err, so it follows the exposure policy set with flooerr.SetExposure.
+func writeJSONError(w http.ResponseWriter, r *http.Request, err error) {
+	flooerr.SetIDHeader(w.Header(), err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]any{"error": flooerr.Public(err)})
+}
+
+// redactedHeaders are shown without their values on the page, as are
+// headers whose names contain one of redactedHeaderWords.
+var (
+	redactedHeaders = map[string]bool{
+		"Authorization":       true,
+		"Cookie":              true,
+		"Proxy-Authorization": true,
+		"Set-Cookie":          true,
+		"X-Api-Key":           true,
+	}
+	redactedHeaderWords = []string{"token", "secret", "key"}
+)
+
+// redacted reports whether the value of the header name is hidden.
+func redacted(name string) bool {
+	if redactedHeaders[http.CanonicalHeaderKey(name)] {
+		return true
+	}
+	lower := strings.ToLower(name)
+	for _, word := range redactedHeaderWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+type page struct {
+	Title   string
+	ID      string
+	Method  string
+	URL     string
+	Headers []entry
+	Layers  []layer
+}
+
+type layer struct {
+	Text      string
+	IsFlooErr bool
+	Code      string
+	ID        string
+	Op        string
+	Message   string
+	Context   []entry
+	SDC       []entry
+	Frames    []frame
+}
+
+type entry struct {
+	Key   string
+	Value string
+}
+
+type frame struct {
+	Function string
+	Location string
+	InModule bool
+	Source   []internal.SourceLine
+}
+
+func newPage(err error, r *http.Request, opts Options) page {
+	p := page{
+		Title:  err.Error(),
+		ID:     flooerr.GetID(err),
+		Method: r.Method,
+		URL:    r.URL.String(),
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(r.Header)) {
+		value := strings.Join(r.Header[name], ", ")
+		if redacted(name) {
+			value = "[redacted]"
+		}
+		p.Headers = append(p.Headers, entry{Key: name, Value: value})
+	}
+
+	sources := make(map[string][]string)
+	for _, info := range flooerr.ParseChain(err) {
+		if !info.IsFlooErr {
+			p.Layers = append(p.Layers, layer{Text: info.Text})
+			continue
+		}
+
+		rendered := layer{
+			Text:      info.Text,
+			IsFlooErr: true,
+			Code:      info.Code.String(),
+			ID:        info.ID,
+			Op:        info.Op,
+		}
+		if info.Message != info.Text {
+			rendered.Message = info.Message
+		}
+		for _, key := range slices.Sorted(maps.Keys(info.Context)) {
+			rendered.Context = append(rendered.Context, entry{Key: key, Value: fmt.Sprintf("%v", info.Context[key])})
+		}
+		for _, key := range slices.Sorted(maps.Keys(info.SDC)) {
+			rendered.SDC = append(rendered.SDC, entry{Key: key, Value: info.SDC[key]})
+		}
+		for _, stackFrame := range info.StackTrace {
+			f := frame{
+				Function: stackFrame.Function,
+				Location: fmt.Sprintf("%s:%d", stackFrame.File, stackFrame.Line),
+				InModule: internal.InModule(stackFrame.Function, stackFrame.File, opts.ModulePrefix),
+			}
+			if f.InModule {
+				f.Source = internal.SourceSnippet(sources, stackFrame.File, stackFrame.Line, opts.SourceLines)
+			}
+			rendered.Frames = append(rendered.Frames, f)
+		}
+		p.Layers = append(p.Layers, rendered)
+	}
+	return p
+}
+
+var pageTemplate = template.Must(template.New("devpage").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", sans-serif; margin: 0; background: #f6f7f9; color: #1d2330; }
+header { background: #b3261e; color: #fff; padding: 24px 32px; }
+header h1 { margin: 0 0 8px; font-size: 22px; word-break: break-word; }
+header p { margin: 0; opacity: .85; font-family: monospace; }
+main { padding: 16px 32px 48px; }
+section { background: #fff; border: 1px solid #dde1e7; border-radius: 6px; margin: 16px 0; padding: 16px 20px; }
+h2 { font-size: 16px; margin: 0 0 12px; }
+h3 { font-size: 13px; text-transform: uppercase; color: #5b6472; margin: 16px 0 6px; }
+.code { background: #b3261e; color: #fff; border-radius: 4px; padding: 2px 8px; font-size: 12px; font-family: monospace; margin-left: 8px; }
+.meta { color: #5b6472; font-family: monospace; font-size: 12px; }
+table { border-collapse: collapse; font-family: monospace; font-size: 13px; }
+td { padding: 2px 16px 2px 0; vertical-align: top; word-break: break-all; }
+td:first-child { color: #2a5db0; white-space: nowrap; }
+.frame { font-family: monospace; font-size: 13px; margin: 6px 0; }
+.frame.dim { opacity: .45; }
+.frame .location { color: #5b6472; padding-left: 16px; }
+pre { background: #1d2330; color: #d5dae3; padding: 8px 12px; border-radius: 4px; overflow-x: auto; margin: 4px 0 8px 16px; }
+pre .current { background: #5c1f1b; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<p>{{.Method}} {{.URL}}{{if .ID}} &middot; error {{.ID}}{{end}}</p>
+</header>
+<main>
+{{range $i, $layer := .Layers}}
+<section>
+<h2>{{if $i}}caused by: {{end}}{{$layer.Text}}{{if $layer.Code}}<span class="code">{{$layer.Code}}</span>{{end}}</h2>
+{{if $layer.IsFlooErr}}<div class="meta">{{if $layer.ID}}id {{$layer.ID}}{{end}}{{if $layer.Op}} &middot; op {{$layer.Op}}{{end}}{{if $layer.Message}} &middot; message {{$layer.Message}}{{end}}</div>{{end}}
+{{if $layer.Context}}<h3>Context</h3>
+<table>{{range $layer.Context}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>{{end}}</table>{{end}}
+{{if $layer.SDC}}<h3>SDC</h3>
+<table>{{range $layer.SDC}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>{{end}}</table>{{end}}
+{{if $layer.Frames}}<h3>Stack</h3>
+{{range $layer.Frames}}<div class="frame{{if not .InModule}} dim{{end}}">{{.Function}}<div class="location">{{.Location}}</div>
+{{if .Source}}<pre>{{range .Source}}<span{{if .Current}} class="current"{{end}}>{{printf "%4d" .Number}} | {{.Text}}</span>
+{{end}}</pre>{{end}}</div>
+{{end}}{{end}}
+</section>
+{{end}}
+<section>
+<h2>Request</h2>
+<table>{{range .Headers}}<tr><td>{{.Key}}</td><td>{{.Value}}</td></tr>{{end}}</table>
+</section>
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,192 @@
+package devpage
+
+import (
+	"core-common-go/flooerr"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func devPageHandler(err error) http.Handler {
+	return Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return err
+	}, Options{Enabled: true})
+}
+
+func devPageRequest(accept string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/users/42?debug=1", nil)
+	r.Header.Set("Accept", accept)
+	r.Header.Set("Authorization", "Bearer secret-token")
+	r.Header.Set("X-Request-Id", "req_1")
+	r.Header.Set("X-Api-Key", "secret-api-key")
+	r.Header.Set("X-Session-Token", "secret-session")
+	return r
+}
+
+func devPageError() error {
+	inner := flooerr.Message("find failed").
+		WithCode("NOT_FOUND").
+		WithContext("query", "<script>alert(1)</script>").
+		WithSDC("db", "primary").
+		Error(errors.New("sql: no rows"), "find failed")
+	return flooerr.Op("user.Get").Wrap(inner, "get user")
+}
+
+func TestHandler_HTML(t *testing.T) {
+	err := devPageError()
+	recorder := httptest.NewRecorder()
+	devPageHandler(err).ServeHTTP(recorder, devPageRequest("text/html,application/xhtml+xml,*/*;q=0.8"))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", recorder.Code)
+	}
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/html; charset=utf-8" {
+		t.Errorf("Unexpected content type '%s'", contentType)
+	}
+
+	if recorder.Header().Get(flooerr.HeaderErrorID) != flooerr.GetID(err) {
+		t.Error("Expected the error ID header")
+	}
+
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		"<h1>user.Get: find failed; caused by: sql: no rows</h1>",
+		`<span class="code">NOT_FOUND</span>`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		"<td>db</td><td>primary</td>",
+		"core-common-go/flooerr/devpage.devPageError",
+		`WithSDC(&#34;db&#34;, &#34;primary&#34;).`,
+		"<td>X-Request-Id</td><td>req_1</td>",
+		"<td>Authorization</td><td>[redacted]</td>",
+		"<td>X-Api-Key</td><td>[redacted]</td>",
+		"<td>X-Session-Token</td><td>[redacted]</td>",
+		"GET /users/42?debug=1",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected page to contain %q", expected)
+		}
+	}
+
+	if strings.Contains(body, "<script>") || strings.Contains(body, "secret") {
+		t.Error("Expected context to be escaped and credentials redacted")
+	}
+}
+
+func TestHandler_NonHTML(t *testing.T) {
+	for _, accept := range []string{"application/json", "*/*", "", "text/html;q=0"} {
+		recorder := httptest.NewRecorder()
+		devPageHandler(devPageError()).ServeHTTP(recorder, devPageRequest(accept))
+
+		if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("Accept %q: expected the JSON fallback, got '%s'", accept, contentType)
+		}
+	}
+}
+
+func TestHandler_FallbackExposure(t *testing.T) {
+	serve := func() flooerr.PublicError {
+		recorder := httptest.NewRecorder()
+		devPageHandler(devPageError()).ServeHTTP(recorder, devPageRequest("application/json"))
+		var body struct{ Error flooerr.PublicError }
+		if decodeErr := json.Unmarshal(recorder.Body.Bytes(), &body); decodeErr != nil {
+			t.Fatal(decodeErr)
+		}
+		return body.Error
+	}
+
+	view := serve()
+	if view.ID == "" || view.Message != "The requested resource was not found." || view.Detail != "" {
+		t.Errorf("Expected only the public view by default, got %+v", view)
+	}
+
+	defer flooerr.SetExposure(flooerr.SetExposure(flooerr.ExposeInternalMessage))
+	if view := serve(); view.Detail != "user.Get: find failed; caused by: sql: no rows" {
+		t.Errorf("Expected the internal message to be exposed, got %+v", view)
+	}
+}
+
+func TestHandler_Disabled(t *testing.T) {
+	fallbackCalled := false
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return flooerr.Error("failed")
+	}, Options{Fallback: func(w http.ResponseWriter, r *http.Request, err error) {
+		fallbackCalled = true
+		w.WriteHeader(http.StatusTeapot)
+	}})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, devPageRequest("text/html"))
+	if !fallbackCalled || recorder.Code != http.StatusTeapot {
+		t.Error("Expected the fallback unless the page is enabled")
+	}
+
+	t.Setenv(EnvEnable, "1")
+	handler = Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return flooerr.Error("failed")
+	}, Options{})
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, devPageRequest("text/html"))
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/html; charset=utf-8" {
+		t.Errorf("Expected %s=1 to enable the page, got '%s'", EnvEnable, contentType)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"Authorization":   true,
+		"Set-Cookie":      true,
+		"X-Api-Key":       true,
+		"X-Auth-Token":    true,
+		"X-Client-Secret": true,
+		"Accept":          false,
+		"X-Request-Id":    false,
+	} {
+		if redacted(name) != expected {
+			t.Errorf("%s: expected redacted = %t", name, expected)
+		}
+	}
+}
+
+func TestHandler_NoError(t *testing.T) {
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.Write([]byte("ok"))
+		return nil
+	}, Options{Enabled: true})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, devPageRequest("text/html"))
+
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ok" {
+		t.Errorf("Unexpected response %d %q", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestHandler_AlreadyWritten(t *testing.T) {
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return flooerr.Error("late failure")
+	}, Options{Enabled: true})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, devPageRequest("text/html"))
+
+	if recorder.Code != http.StatusAccepted || recorder.Body.Len() != 0 {
+		t.Errorf("Expected the started response to be left alone, got %d %q", recorder.Code, recorder.Body.String())
+	}
+
+	var late error
+	handler = Handler(func(w http.ResponseWriter, r *http.Request) error {
+		w.Write([]byte("partial"))
+		return flooerr.Error("late failure")
+	}, Options{Enabled: true, Late: func(r *http.Request, err error) { late = err }})
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, devPageRequest("text/html"))
+
+	if late == nil || late.Error() != "late failure" || recorder.Body.String() != "partial" {
+		t.Errorf("Expected the late error to be reported, got %v and %q", late, recorder.Body.String())
+	}
+}
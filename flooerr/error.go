@@ -4,8 +4,14 @@ import (
 	"core-common-go/flooerr/internal"
 	"fmt"
 	"runtime"
+	"sync/atomic"
+	"time"
 )
 
+// ErrCode is the type of FlooErr codes. It lets packages outside flooerr
+// declare typed code constants.
+type ErrCode = internal.Code
+
 type FlooErr interface {
 	error
 	Code() internal.Code
@@ -25,6 +31,13 @@ type err struct {
 	stackTrace    []stacktrace
 	context       map[string]any
 	sdc           map[string]string
+	sentinel      bool
+	op            string
+	id            ErrorID
+	time          time.Time
+	publicMessage string
+	// hooking is set while the build hooks of the error run.
+	hooking atomic.Bool
 }
 
 func (e *err) Code() internal.Code {
@@ -48,14 +61,14 @@ func (e *err) StackTrace() []stacktrace {
 
 	for {
 		frame, more := frames.Next()
-		if !more {
-			break
-		}
 		traces = append(traces, stacktrace{
 			Function: frame.Function,
 			File:     frame.File,
 			Line:     frame.Line,
 		})
+		if !more {
+			break
+		}
 	}
 
 	e.stackTrace = traces
@@ -66,16 +79,55 @@ func (e *err) Unwrap() error {
 	return e.cause
 }
 
+// Context returns the context of the layer. Errors built without context
+// hold no map, so an empty one is returned; writes to it are not kept.
 func (e *err) Context() map[string]any {
+	if e.context == nil {
+		return map[string]any{}
+	}
 	return e.context
 }
 
+// SDC returns the SDC of the layer. Like Context, it returns an empty map
+// for errors built without SDC.
 func (e *err) SDC() map[string]string {
+	if e.sdc == nil {
+		return map[string]string{}
+	}
 	return e.sdc
 }
 
+// Op returns the logical operation recorded with WithOp, if any.
+func (e *err) Op() string {
+	return e.op
+}
+
+// PublicMessage returns the message set with WithPublicMessage, if any.
+func (e *err) PublicMessage() string {
+	return e.publicMessage
+}
+
+// Error renders the layer and its causes. A layer with an op renders as
+// "op: cause", like the path returned by OpPath. A layer whose message is
+// empty or repeats the message of the FlooErr it wraps renders as just its
+// cause, so "failed; caused by: failed" collapses to "failed".
 func (e *err) Error() string {
+	if e.op != "" {
+		switch {
+		case e.cause == nil && e.errMessage == "":
+			return e.op
+		case e.cause == nil:
+			return e.op + ": " + e.errMessage
+		}
+		if inner, ok := e.cause.(*err); ok && inner.op == e.op {
+			return e.cause.Error()
+		}
+		return e.op + ": " + e.cause.Error()
+	}
 	if e.cause != nil {
+		if inner, ok := e.cause.(*err); e.errMessage == "" || ok && inner.errMessage == e.errMessage {
+			return e.cause.Error()
+		}
 		return fmt.Sprintf("%s; caused by: %v", e.errMessage, e.cause)
 	}
 	return e.errMessage
@@ -89,6 +141,11 @@ func Code(code internal.Code) *internal.ErrProps {
 	return internal.Create().WithCode(code.String())
 }
 
+// Op starts a builder for a layer recording the logical operation op.
+func Op(op string) *internal.ErrProps {
+	return internal.Create().WithOp(op)
+}
+
 func StackTrace() *internal.ErrProps {
 	return internal.Create().WithStackTrace(true)
 }
@@ -102,53 +159,53 @@ func SDC(key string, value map[string]string) *internal.ErrProps {
 }
 
 func Error(message string) error {
-	return internal.Create().Build(nil, message)
+	return internal.Create().BuildSkip(0, nil, message)
 }
 
 func ErrorF(format string, args ...any) error {
-	return internal.Create().Build(nil, fmt.Sprintf(format, args...))
+	return internal.Create().BuildSkip(0, nil, fmt.Sprintf(format, args...))
 }
 
+// Wrap wraps err with message. A nil err still produces a new error (or
+// panics in strict nil-wrap mode); use WrapIf to propagate nil.
 func Wrap(err error, message string) error {
-	return internal.Create().Build(err, message)
+	internal.CheckNilCause(err, "Wrap")
+	return internal.Create().BuildSkip(0, err, message)
 }
 
+// WrapF is Wrap with a formatted message.
 func WrapF(err error, format string, args ...any) error {
-	return internal.Create().Build(err, fmt.Sprintf(format, args...))
+	internal.CheckNilCause(err, "WrapF")
+	return internal.Create().BuildSkip(0, err, fmt.Sprintf(format, args...))
 }
 
 // newErr creates a new err struct. This is used by internal package.
-func newErr(
-	message string,
-	errMessage string,
-	code internal.Code,
-	cause error,
-	stackTracePTR []uintptr,
-	context map[string]any,
-	sdc map[string]string,
-) FlooErr {
-	return &err{
-		message:       message,
-		errMessage:    errMessage,
+func newErr(args internal.BuildArgs) FlooErr {
+	code := args.Code
+	if args.InheritCode && code == "" {
+		code = EffectiveCode(args.Cause)
+	}
+	now := time.Now()
+	built := &err{
+		message:       args.Message,
+		errMessage:    args.ErrMessage,
 		code:          code,
-		cause:         cause,
-		stackTracePTR: stackTracePTR,
+		cause:         args.Cause,
+		stackTracePTR: args.StackTracePTR,
 		stackTrace:    nil,
-		context:       context,
-		sdc:           sdc,
+		context:       args.Context,
+		sdc:           withProcessSDC(args.SDC),
+		op:            args.Op,
+		id:            newID(now),
+		time:          now,
+		publicMessage: args.PublicMessage,
 	}
+	runHooks(built)
+	return built
 }
 
 func init() {
-	internal.SetBuildErrFunc(func(
-		message string,
-		errMessage string,
-		code internal.Code,
-		cause error,
-		stackTracePTR []uintptr,
-		context map[string]any,
-		sdc map[string]string,
-	) error {
-		return newErr(message, errMessage, code, cause, stackTracePTR, context, sdc)
+	internal.SetBuildErrFunc(func(args internal.BuildArgs) error {
+		return newErr(args)
 	})
 }
@@ -218,6 +218,17 @@ func TestErr_StackTrace(t *testing.T) {
 	}
 }
 
+// StackTrace used to drop the last captured frame, which left stacks with a
+// single frame empty.
+func TestErr_StackTrace_LastFrame(t *testing.T) {
+	defer SetStackMode(SetStackMode(StackReturn))
+
+	stackTrace := GetStackTrace(Error("test error"))
+	if len(stackTrace) != 1 || stackTrace[0].Function != "core-common-go/flooerr.TestErr_StackTrace_LastFrame" {
+		t.Errorf("Expected the single build frame, got %v", stackTrace)
+	}
+}
+
 func TestErr_StackTrace_Disabled(t *testing.T) {
 	err := Message("test").
 		WithStackTrace(false).
@@ -0,0 +1,170 @@
+package fault
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by LoadEnv.
+const (
+	// EnvSpec holds inline point specs, see ParseSpec.
+	EnvSpec = "FLOOERR_FAULTS"
+	// EnvFile holds the path of a JSON config file, see LoadFile.
+	EnvFile = "FLOOERR_FAULTS_FILE"
+)
+
+func init() {
+	if err := LoadEnv(); err != nil {
+		fmt.Fprintln(os.Stderr, "fault:", err)
+	}
+}
+
+// fileConfig is the JSON form of Config, with Latency as a duration string.
+type fileConfig struct {
+	Code        string  `json:"code"`
+	Message     string  `json:"message"`
+	Probability float64 `json:"probability"`
+	Nth         int64   `json:"nth"`
+	Latency     string  `json:"latency"`
+}
+
+// LoadFile activates the points of a JSON config file mapping point names
+// to configs:
+//
+//	{"billing.charge": {"code": "PAYMENT_DECLINED", "probability": 0.1, "latency": "200ms"}}
+func LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file map[string]fileConfig
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	configs := make(map[string]Config, len(file))
+	for name, fc := range file {
+		config := Config{
+			Code:        fc.Code,
+			Message:     fc.Message,
+			Probability: fc.Probability,
+			Nth:         fc.Nth,
+		}
+		if fc.Latency != "" {
+			if config.Latency, err = time.ParseDuration(fc.Latency); err != nil {
+				return fmt.Errorf("%s: point %s: %w", path, name, err)
+			}
+		}
+		if err := config.validate(); err != nil {
+			return fmt.Errorf("%s: point %s: %w", path, name, err)
+		}
+		configs[name] = config
+	}
+
+	for name, config := range configs {
+		Enable(name, config)
+	}
+	return nil
+}
+
+// LoadEnv activates the points configured by EnvFile and EnvSpec. It runs
+// at package initialization; errors are reported on stderr there.
+func LoadEnv() error {
+	if path := os.Getenv(EnvFile); path != "" {
+		if err := LoadFile(path); err != nil {
+			return fmt.Errorf("%s: %w", EnvFile, err)
+		}
+	}
+	if spec := os.Getenv(EnvSpec); spec != "" {
+		configs, err := ParseSpec(spec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", EnvSpec, err)
+		}
+		for name, config := range configs {
+			Enable(name, config)
+		}
+	}
+	return nil
+}
+
+// ParseSpec parses inline point specs separated by semicolons, each a point
+// name followed by comma-separated options:
+//
+//	billing.charge:code=PAYMENT_DECLINED,message=card declined,probability=0.5;db.query:latency=50ms
+//
+// Options are code, message, probability, nth and latency.
+func ParseSpec(spec string) (map[string]Config, error) {
+	configs := make(map[string]Config)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, options, _ := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("missing point name in %q", entry)
+		}
+
+		var config Config
+		for _, option := range strings.Split(options, ",") {
+			if strings.TrimSpace(option) == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(option, "=")
+			if !ok {
+				return nil, fmt.Errorf("point %s: option %q is not key=value", name, option)
+			}
+			if err := config.set(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("point %s: %w", name, err)
+			}
+		}
+		if err := config.validate(); err != nil {
+			return nil, fmt.Errorf("point %s: %w", name, err)
+		}
+		configs[name] = config
+	}
+	return configs, nil
+}
+
+func (c *Config) set(key, value string) error {
+	var err error
+	switch key {
+	case "code":
+		c.Code = value
+	case "message":
+		c.Message = value
+	case "probability":
+		c.Probability, err = strconv.ParseFloat(value, 64)
+	case "nth":
+		c.Nth, err = strconv.ParseInt(value, 10, 64)
+	case "latency":
+		c.Latency, err = time.ParseDuration(value)
+	default:
+		return fmt.Errorf("unknown option %q", key)
+	}
+	if err != nil {
+		return fmt.Errorf("option %s: %w", key, err)
+	}
+	return nil
+}
+
+func (c Config) validate() error {
+	if c.Probability < 0 || c.Probability > 1 {
+		return fmt.Errorf("probability %v is outside [0, 1]", c.Probability)
+	}
+	if c.Nth < 0 {
+		return fmt.Errorf("nth %d is negative", c.Nth)
+	}
+	if c.Latency < 0 {
+		return fmt.Errorf("latency %v is negative", c.Latency)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package fault
+
+import (
+	"context"
+	"core-common-go/flooerr"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSpec(t *testing.T) {
+	configs, err := ParseSpec("billing.charge:code=PAYMENT_DECLINED,message=card declined,probability=0.5,nth=2; db.query:latency=50ms;")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := Config{Code: "PAYMENT_DECLINED", Message: "card declined", Probability: 0.5, Nth: 2}
+	if configs["billing.charge"] != expected {
+		t.Errorf("Unexpected config %+v", configs["billing.charge"])
+	}
+
+	if configs["db.query"].Latency != 50*time.Millisecond {
+		t.Errorf("Unexpected latency %v", configs["db.query"].Latency)
+	}
+}
+
+func TestParseSpec_Errors(t *testing.T) {
+	specs := []string{
+		":code=X",
+		"p:code",
+		"p:unknown=1",
+		"p:probability=2",
+		"p:nth=-1",
+		"p:latency=soon",
+	}
+
+	for _, spec := range specs {
+		if _, err := ParseSpec(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	defer Reset()
+	path := filepath.Join(t.TempDir(), "faults.json")
+	data := `{"billing.charge": {"code": "PAYMENT_DECLINED", "latency": "1ms"}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadFile(path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if flooerr.GetCodeString(Point(context.Background(), "billing.charge")) != "PAYMENT_DECLINED" {
+		t.Error("Expected point from file to be active")
+	}
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"unknown.json": `{"p": {"cod": "X"}}`,
+		"latency.json": `{"p": {"latency": "soon"}}`,
+		"invalid.json": `{"p": {"probability": 3}}`,
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := LoadFile(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if err := LoadFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	defer Reset()
+	t.Setenv(EnvSpec, "env.point:code=FROM_ENV")
+
+	if err := LoadEnv(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if flooerr.GetCodeString(Point(context.Background(), "env.point")) != "FROM_ENV" {
+		t.Error("Expected point from environment to be active")
+	}
+
+	t.Setenv(EnvSpec, "noop.point")
+	t.Setenv(EnvFile, "")
+	if err := LoadEnv(); err != nil {
+		t.Errorf("Expected point without options to be valid, got %v", err)
+	}
+
+	t.Setenv(EnvFile, filepath.Join(t.TempDir(), "missing.json"))
+	if err := LoadEnv(); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
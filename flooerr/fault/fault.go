@@ -0,0 +1,186 @@
+// Package fault provides named fault-injection points that return FlooErrs.
+//
+// Code declares points on its failure paths:
+//
+//	if err := fault.Point(ctx, "billing.charge"); err != nil {
+//		return err
+//	}
+//
+// Points do nothing until activated by a test (Enable, WithPoint), a config
+// file (LoadFile) or the FLOOERR_FAULTS environment variables (LoadEnv). A
+// disabled point costs a single atomic load.
+package fault
+
+import (
+	"context"
+	"core-common-go/flooerr"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config describes how an active point fails.
+type Config struct {
+	// Code and Message of the returned FlooErr. When both are empty the
+	// point only injects Latency and returns nil.
+	Code    string
+	Message string
+	// Probability of failing a call, in (0, 1]. Zero means always.
+	Probability float64
+	// Nth fails only the nth call (1-based) after activation. Zero means
+	// every call.
+	Nth int64
+	// Latency is waited before the point returns, unless ctx is done first.
+	Latency time.Duration
+}
+
+type point struct {
+	config Config
+	calls  atomic.Int64
+}
+
+type contextKey struct{}
+
+var (
+	// enabled is true while any global point is active or once a point
+	// was activated through a context, so disabled points return early.
+	enabled atomic.Bool
+	// contextPoints is sticky: contexts carrying points cannot be tracked.
+	contextPoints atomic.Bool
+
+	mu     sync.RWMutex
+	points = make(map[string]*point)
+
+	// randFloat is replaced in tests.
+	randFloat = rand.Float64
+)
+
+// Point returns the configured error if the named point is active and
+// triggers, and nil otherwise. Points activated on ctx take precedence over
+// global ones.
+func Point(ctx context.Context, name string) error {
+	if !enabled.Load() {
+		return nil
+	}
+	p := lookup(ctx, name)
+	if p == nil {
+		return nil
+	}
+	return p.fire(ctx, name)
+}
+
+func lookup(ctx context.Context, name string) *point {
+	if ctx != nil && contextPoints.Load() {
+		if scoped, ok := ctx.Value(contextKey{}).(map[string]*point); ok {
+			if p, ok := scoped[name]; ok {
+				return p
+			}
+		}
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	return points[name]
+}
+
+func (p *point) fire(ctx context.Context, name string) error {
+	call := p.calls.Add(1)
+	if p.config.Nth > 0 && call != p.config.Nth {
+		return nil
+	}
+	if p.config.Probability > 0 && p.config.Probability < 1 && randFloat() >= p.config.Probability {
+		return nil
+	}
+
+	if p.config.Latency > 0 {
+		if err := wait(ctx, p.config.Latency); err != nil {
+			return flooerr.Message("fault latency interrupted").
+				WithContext("fault_point", name).
+				BuildSkip(1, err, "fault latency interrupted")
+		}
+	}
+	if p.config.Code == "" && p.config.Message == "" {
+		return nil
+	}
+
+	message := p.config.Message
+	if message == "" {
+		message = "fault injected at " + name
+	}
+	return flooerr.Message(message).
+		WithCode(p.config.Code).
+		WithContext("fault_point", name).
+		WithContext("fault_call", call).
+		BuildSkip(1, nil, message)
+}
+
+func wait(ctx context.Context, latency time.Duration) error {
+	if ctx == nil {
+		time.Sleep(latency)
+		return nil
+	}
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Enable activates the named point globally and returns a function that
+// disables it again. Enabling an active point replaces its configuration and
+// resets its call count.
+func Enable(name string, config Config) (disable func()) {
+	p := &point{config: config}
+	mu.Lock()
+	points[name] = p
+	enabled.Store(true)
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if points[name] == p {
+			delete(points, name)
+		}
+		enabled.Store(len(points) > 0 || contextPoints.Load())
+	}
+}
+
+// Reset disables every global point.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	points = make(map[string]*point)
+	enabled.Store(contextPoints.Load())
+}
+
+// Active returns the names of the globally active points.
+func Active() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(points))
+	for name := range points {
+		names = append(names, name)
+	}
+	return names
+}
+
+// WithPoint returns a context in which the named point is active. This
+// isolates parallel tests from each other; the point's call count is shared
+// by every use of the returned context.
+func WithPoint(ctx context.Context, name string, config Config) context.Context {
+	scoped := make(map[string]*point)
+	if parent, ok := ctx.Value(contextKey{}).(map[string]*point); ok {
+		for n, p := range parent {
+			scoped[n] = p
+		}
+	}
+	scoped[name] = &point{config: config}
+
+	contextPoints.Store(true)
+	enabled.Store(true)
+	return context.WithValue(ctx, contextKey{}, scoped)
+}
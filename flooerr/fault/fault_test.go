@@ -0,0 +1,161 @@
+package fault
+
+import (
+	"context"
+	"core-common-go/flooerr"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPoint_Disabled(t *testing.T) {
+	Reset()
+	if err := Point(context.Background(), "billing.charge"); err != nil {
+		t.Errorf("Expected nil for inactive point, got %v", err)
+	}
+}
+
+func TestEnable(t *testing.T) {
+	disable := Enable("billing.charge", Config{Code: "PAYMENT_DECLINED", Message: "card declined"})
+
+	err := Point(context.Background(), "billing.charge")
+	if flooerr.GetCodeString(err) != "PAYMENT_DECLINED" {
+		t.Errorf("Expected code 'PAYMENT_DECLINED', got '%s'", flooerr.GetCodeString(err))
+	}
+
+	if err.Error() != "card declined" {
+		t.Errorf("Expected 'card declined', got '%s'", err.Error())
+	}
+
+	if flooerr.GetContextValue(err, "fault_point") != "billing.charge" {
+		t.Errorf("Expected fault_point in context, got %v", flooerr.GetContext(err))
+	}
+
+	stackTrace := flooerr.GetStackTrace(err)
+	if len(stackTrace) > 0 && !strings.HasSuffix(stackTrace[0].Function, "TestEnable") {
+		t.Errorf("Expected stack to start at the caller of Point, got '%s'", stackTrace[0].Function)
+	}
+
+	if err := Point(context.Background(), "other"); err != nil {
+		t.Errorf("Expected nil for another point, got %v", err)
+	}
+
+	disable()
+	if err := Point(context.Background(), "billing.charge"); err != nil {
+		t.Errorf("Expected nil after disable, got %v", err)
+	}
+}
+
+func TestEnable_DefaultMessage(t *testing.T) {
+	defer Enable("db.query", Config{Code: "DB_ERR"})()
+
+	err := Point(context.Background(), "db.query")
+	if err == nil || err.Error() != "fault injected at db.query" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestEnable_Nth(t *testing.T) {
+	defer Enable("nth", Config{Code: "X", Nth: 3})()
+
+	for call := 1; call <= 5; call++ {
+		err := Point(context.Background(), "nth")
+		if (err != nil) != (call == 3) {
+			t.Errorf("Call %d: unexpected result %v", call, err)
+		}
+	}
+}
+
+func TestEnable_Probability(t *testing.T) {
+	defer func(original func() float64) { randFloat = original }(randFloat)
+	values := []float64{0.1, 0.9}
+	randFloat = func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+	defer Enable("flaky", Config{Code: "X", Probability: 0.5})()
+
+	if err := Point(context.Background(), "flaky"); err == nil {
+		t.Error("Expected failure when random value is below probability")
+	}
+
+	if err := Point(context.Background(), "flaky"); err != nil {
+		t.Error("Expected success when random value is above probability")
+	}
+}
+
+func TestEnable_LatencyOnly(t *testing.T) {
+	defer Enable("slow", Config{Latency: 10 * time.Millisecond})()
+
+	start := time.Now()
+	if err := Point(context.Background(), "slow"); err != nil {
+		t.Errorf("Expected nil for latency-only point, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("Expected at least 10ms latency, got %v", elapsed)
+	}
+}
+
+func TestEnable_LatencyCanceled(t *testing.T) {
+	defer Enable("slow", Config{Code: "X", Latency: time.Hour})()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Point(ctx, "slow")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWithPoint(t *testing.T) {
+	ctx := WithPoint(context.Background(), "scoped", Config{Code: "SCOPED"})
+	ctx = WithPoint(ctx, "second", Config{Code: "SECOND"})
+
+	if flooerr.GetCodeString(Point(ctx, "scoped")) != "SCOPED" {
+		t.Error("Expected scoped point to fire on its context")
+	}
+
+	if flooerr.GetCodeString(Point(ctx, "second")) != "SECOND" {
+		t.Error("Expected parent points to be kept")
+	}
+
+	if err := Point(context.Background(), "scoped"); err != nil {
+		t.Errorf("Expected scoped point to be inactive elsewhere, got %v", err)
+	}
+}
+
+func TestWithPoint_OverridesGlobal(t *testing.T) {
+	defer Enable("both", Config{Code: "GLOBAL"})()
+	ctx := WithPoint(context.Background(), "both", Config{Code: "SCOPED"})
+
+	if flooerr.GetCodeString(Point(ctx, "both")) != "SCOPED" {
+		t.Error("Expected context point to take precedence")
+	}
+
+	if flooerr.GetCodeString(Point(nil, "both")) != "GLOBAL" {
+		t.Error("Expected global point with nil context")
+	}
+}
+
+func TestReset(t *testing.T) {
+	Enable("a", Config{Code: "A"})
+	Enable("b", Config{Code: "B"})
+	Reset()
+
+	if len(Active()) != 0 {
+		t.Errorf("Expected no active points, got %v", Active())
+	}
+}
+
+func BenchmarkPoint_Disabled(b *testing.B) {
+	Reset()
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Point(ctx, "billing.charge")
+	}
+}
@@ -0,0 +1,26 @@
+package flooerrtest_test
+
+import (
+	"core-common-go/flooerr/flooerrtest"
+	"flag"
+	"testing"
+)
+
+// update is declared like the golden-file flags of other test packages; it
+// must not clash with the flag of flooerrtest.
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func TestUpdateFlag(t *testing.T) {
+	if flag.Lookup("flooerrtest.update") == nil {
+		t.Error("Expected flooerrtest to register -flooerrtest.update")
+	}
+	if flag.Lookup("update").Usage != "rewrite golden files" {
+		t.Error("Expected -update to belong to this package")
+	}
+	if *update {
+		t.Skip("golden files are being rewritten")
+	}
+	if flooerrtest.Normalize("main.go:12") != "main.go:LINE" {
+		t.Error("Expected flooerrtest to be usable")
+	}
+}
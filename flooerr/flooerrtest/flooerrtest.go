@@ -0,0 +1,180 @@
+// Package flooerrtest provides test assertions and golden-file helpers for
+// FlooErrs.
+//
+// Golden files are rewritten by running the tests with
+// -flooerrtest.update. The flag is namespaced rather than the usual -update
+// because flags are global to a test binary: a test package that imports
+// flooerrtest and declares its own -update flag for other golden files
+// would otherwise panic at start-up with a redefined flag.
+package flooerrtest
+
+import (
+	"core-common-go/flooerr"
+	"core-common-go/flooerr/internal"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// update is namespaced, see the package doc.
+var update = flag.Bool("flooerrtest.update", false, "rewrite flooerrtest golden files")
+
+// AssertCode fails the test unless the effective code of err, the outermost
+// non-empty code in its chain, is code.
+func AssertCode(t testing.TB, err error, code flooerr.ErrCode) bool {
+	t.Helper()
+	if err == nil {
+		t.Errorf("expected error with code %q, got nil", code)
+		return false
+	}
+	if !flooerr.IsFlooErr(err) {
+		t.Errorf("expected error with code %q, got non-FlooErr %T: %v", code, err, err)
+		return false
+	}
+	if actual := flooerr.EffectiveCode(err); actual != code {
+		t.Errorf("expected code %q, got %q (error: %v)", code, actual, err)
+		return false
+	}
+	return true
+}
+
+// AssertChain fails the test unless the FlooErr layers of err carry codes,
+// outermost first. Layers that are not FlooErrs are skipped; layers without
+// a code are matched by "".
+func AssertChain(t testing.TB, err error, codes ...flooerr.ErrCode) bool {
+	t.Helper()
+	var actual []flooerr.ErrCode
+	for _, info := range flooerr.ParseChain(err) {
+		if info.IsFlooErr {
+			actual = append(actual, info.Code)
+		}
+	}
+	if !reflect.DeepEqual(actual, codes) && !(len(actual) == 0 && len(codes) == 0) {
+		t.Errorf("expected chain codes %q, got %q (error: %v)", codes, actual, err)
+		return false
+	}
+	return true
+}
+
+// AssertContext fails the test unless a layer of err has key in its context
+// with a value deeply equal to want. The nearest layer with the key wins.
+func AssertContext(t testing.TB, err error, key string, want any) bool {
+	t.Helper()
+	context := flooerr.MergedContext(err)
+	actual, ok := context[key]
+	if !ok {
+		t.Errorf("expected context key %q, not found in %v", key, context)
+		return false
+	}
+	if !reflect.DeepEqual(actual, want) {
+		t.Errorf("expected context[%q] = %#v, got %#v", key, want, actual)
+		return false
+	}
+	return true
+}
+
+// AssertSDC fails the test unless a layer of err has key in its SDC with
+// value want. The nearest layer with the key wins.
+func AssertSDC(t testing.TB, err error, key string, want string) bool {
+	t.Helper()
+	sdc := flooerr.MergedSDC(err)
+	actual, ok := sdc[key]
+	if !ok {
+		t.Errorf("expected SDC key %q, not found in %v", key, sdc)
+		return false
+	}
+	if actual != want {
+		t.Errorf("expected SDC[%q] = %q, got %q", key, want, actual)
+		return false
+	}
+	return true
+}
+
+// Golden compares the %+v rendering of err with testdata/<test name>.golden.
+// Run the tests with -flooerrtest.update to rewrite the file.
+func Golden(t testing.TB, err error) {
+	t.Helper()
+	compareGolden(t, ".golden", Normalize(fmt.Sprintf("%+v", err)))
+}
+
+// GoldenJSON compares the indented JSON encoding of err with
+// testdata/<test name>.json.golden. Run the tests with -flooerrtest.update
+// to rewrite the file.
+func GoldenJSON(t testing.TB, err error) {
+	t.Helper()
+	data, marshalErr := json.MarshalIndent(err, "", "  ")
+	if marshalErr != nil {
+		t.Fatalf("marshalling error: %v", marshalErr)
+	}
+	compareGolden(t, ".json.golden", Normalize(string(data)+"\n"))
+}
+
+var (
+	goLinePattern   = regexp.MustCompile(`(\.go):\d+`)
+	jsonLinePattern = regexp.MustCompile(`("line": )\d+`)
+	idPattern       = regexp.MustCompile(`(id: |"id": ")[0-9A-Z]{26}`)
+	timePattern     = regexp.MustCompile(`("time": ")[^"]+`)
+)
+
+// Normalize makes output reproducible across machines: the module root and
+// GOROOT are replaced by $MODULE and $GOROOT, line numbers by LINE (0 in
+// JSON), error IDs by ID and build times by TIME.
+func Normalize(output string) string {
+	if root := moduleRoot(); root != "" {
+		output = strings.ReplaceAll(output, filepath.ToSlash(root)+"/", "$MODULE/")
+	}
+	if goroot := internal.FrameGOROOT(); goroot != "" {
+		output = strings.ReplaceAll(output, goroot+"/", "$GOROOT/")
+	}
+	output = goLinePattern.ReplaceAllString(output, "${1}:LINE")
+	output = idPattern.ReplaceAllString(output, "${1}ID")
+	output = timePattern.ReplaceAllString(output, "${1}TIME")
+	return jsonLinePattern.ReplaceAllString(output, "${1}0")
+}
+
+func compareGolden(t testing.TB, suffix, actual string) {
+	t.Helper()
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	path := filepath.Join("testdata", name+suffix)
+
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatalf("creating testdata: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -flooerrtest.update to create it): %v", err)
+	}
+	if string(expected) != actual {
+		t.Errorf("output does not match %s (run with -flooerrtest.update to rewrite)\n--- expected\n%s\n--- actual\n%s", path, expected, actual)
+	}
+}
+
+// moduleRoot returns the closest directory at or above the working directory
+// containing a go.mod file, or the working directory if there is none.
+func moduleRoot() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		if filepath.Dir(dir) == dir {
+			return wd
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package flooerrtest
+
+import (
+	"core-common-go/flooerr"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	name     string
+	failures []string
+}
+
+func (r *recorder) Name() string {
+	if r.name != "" {
+		return r.name
+	}
+	return r.TB.Name()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func testError() error {
+	repo := flooerr.Message("find failed").
+		WithCode("NOT_FOUND").
+		WithContext("table", "users").
+		WithSDC("db", "primary").
+		Error(errors.New("sql: no rows"), "find failed")
+	wrapped := flooerr.Wrap(repo, "service failed")
+	return flooerr.Message("get user failed").
+		WithCode("USER_ERR").
+		WithContext("id", 42).
+		Error(wrapped, "get user failed")
+}
+
+func TestAssertCode(t *testing.T) {
+	r := &recorder{TB: t}
+
+	if !AssertCode(r, testError(), "USER_ERR") {
+		t.Errorf("Expected AssertCode to pass, failures: %v", r.failures)
+	}
+
+	if !AssertCode(r, flooerr.Wrap(testError(), "handler failed"), "USER_ERR") {
+		t.Errorf("Expected AssertCode to look past layers without a code, failures: %v", r.failures)
+	}
+
+	if AssertCode(r, testError(), "OTHER") || len(r.failures) != 1 {
+		t.Error("Expected AssertCode to fail for a different code")
+	}
+
+	if AssertCode(r, nil, "USER_ERR") || AssertCode(r, errors.New("plain"), "USER_ERR") {
+		t.Error("Expected AssertCode to fail for nil and non-FlooErr")
+	}
+}
+
+func TestAssertChain(t *testing.T) {
+	r := &recorder{TB: t}
+
+	if !AssertChain(r, testError(), "USER_ERR", "", "NOT_FOUND") {
+		t.Errorf("Expected AssertChain to pass, failures: %v", r.failures)
+	}
+
+	if AssertChain(r, testError(), "USER_ERR", "NOT_FOUND") {
+		t.Error("Expected AssertChain to fail when a layer is missing")
+	}
+
+	if !AssertChain(r, errors.New("plain")) {
+		t.Error("Expected AssertChain with no codes to pass for a plain error")
+	}
+}
+
+func TestAssertContext(t *testing.T) {
+	r := &recorder{TB: t}
+
+	if !AssertContext(r, testError(), "id", 42) || !AssertContext(r, testError(), "table", "users") {
+		t.Errorf("Expected AssertContext to pass, failures: %v", r.failures)
+	}
+
+	if AssertContext(r, testError(), "id", int64(42)) {
+		t.Error("Expected AssertContext to fail for a different type")
+	}
+
+	if AssertContext(r, testError(), "missing", nil) {
+		t.Error("Expected AssertContext to fail for a missing key")
+	}
+}
+
+func TestAssertSDC(t *testing.T) {
+	r := &recorder{TB: t}
+
+	if !AssertSDC(r, testError(), "db", "primary") {
+		t.Errorf("Expected AssertSDC to pass, failures: %v", r.failures)
+	}
+
+	if AssertSDC(r, testError(), "db", "replica") || AssertSDC(r, testError(), "missing", "") {
+		t.Error("Expected AssertSDC to fail")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, testError())
+}
+
+func TestGoldenJSON(t *testing.T) {
+	GoldenJSON(t, testError())
+}
+
+func TestGolden_Mismatch(t *testing.T) {
+	if *update {
+		t.Skip("golden files are being rewritten")
+	}
+
+	r := &recorder{TB: t, name: "TestGolden"}
+	compareGolden(r, ".golden", "different output\n")
+
+	if len(r.failures) != 1 || !strings.Contains(r.failures[0], "does not match") {
+		t.Errorf("Expected mismatch failure, got %v", r.failures)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	root := moduleRoot()
+	input := root + "/pkg/file.go:42\n\"line\": 17"
+
+	expected := "$MODULE/pkg/file.go:LINE\n\"line\": 0"
+	if actual := Normalize(input); actual != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, actual)
+	}
+
+	// The caller of a test is testing.tRunner, in the standard library.
+	_, file, _, _ := runtime.Caller(1)
+	if actual := Normalize(file); !strings.HasPrefix(actual, "$GOROOT/src/testing/") {
+		t.Errorf("Expected GOROOT to be normalized, got '%s'", actual)
+	}
+}
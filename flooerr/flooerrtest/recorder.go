@@ -0,0 +1,52 @@
+package flooerrtest
+
+import (
+	"core-common-go/flooerr"
+	"sync"
+	"testing"
+)
+
+// Recorder captures every FlooErr built while it is registered. Hooks are
+// global, so errors built by parallel tests are captured too.
+type Recorder struct {
+	mu     sync.Mutex
+	errors []flooerr.FlooErr
+}
+
+// NewRecorder starts recording and stops when t finishes.
+func NewRecorder(t testing.TB) *Recorder {
+	r := &Recorder{}
+	t.Cleanup(flooerr.OnBuild(r.record))
+	return r
+}
+
+func (r *Recorder) record(flooErr flooerr.FlooErr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, flooErr)
+}
+
+// Errors returns the recorded errors in build order.
+func (r *Recorder) Errors() []flooerr.FlooErr {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]flooerr.FlooErr(nil), r.errors...)
+}
+
+// Codes returns the codes of the recorded errors in build order.
+func (r *Recorder) Codes() []flooerr.ErrCode {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	codes := make([]flooerr.ErrCode, len(r.errors))
+	for i, flooErr := range r.errors {
+		codes[i] = flooErr.Code()
+	}
+	return codes
+}
+
+// Reset discards the recorded errors.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = nil
+}
@@ -0,0 +1,41 @@
+package flooerrtest
+
+import (
+	"core-common-go/flooerr"
+	"errors"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder(t)
+
+	inner := flooerr.Code("NOT_FOUND").Error(nil, "missing")
+	_ = flooerr.Wrap(inner, "load failed")
+	_ = errors.New("not recorded")
+
+	codes := recorder.Codes()
+	if len(codes) != 2 || codes[0] != "NOT_FOUND" || codes[1] != "" {
+		t.Errorf("Unexpected codes %q", codes)
+	}
+
+	if recorder.Errors()[0] != inner {
+		t.Error("Expected the recorded error to be the built error")
+	}
+
+	recorder.Reset()
+	if len(recorder.Errors()) != 0 {
+		t.Error("Expected Reset to discard recorded errors")
+	}
+}
+
+func TestRecorder_StopsAfterTest(t *testing.T) {
+	var recorder *Recorder
+	t.Run("recording", func(t *testing.T) {
+		recorder = NewRecorder(t)
+	})
+
+	_ = flooerr.Error("after")
+	if len(recorder.Errors()) != 0 {
+		t.Error("Expected the recorder to stop when its test finished")
+	}
+}
@@ -0,0 +1,239 @@
+package flooerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Format implements fmt.Formatter. %s and %v print Error(), %q prints it
+// quoted and %+v prints every layer of the chain with its code, message,
+// context, SDC and stack trace, in the stable text rendering that ParseText
+// reads back:
+//
+//	get user failed
+//	    id: 01K7RZ2Q5E0QF3B6XW1T9M4N8C
+//	    code: USER_ERR
+//	    context:
+//	        id: 42
+//	    stack:
+//	        main.getUser
+//	            /app/main.go:42
+//	caused by: sql: no rows
+func (e *err) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeDetail(s, e)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(flooerr=%s)", verb, e.Error())
+	}
+}
+
+// writeDetail writes the %+v rendering of every layer in the chain.
+func writeDetail(w io.Writer, err error) {
+	for i, layer := range UnwrapChain(err) {
+		if i > 0 {
+			io.WriteString(w, "caused by: ")
+		}
+		flooErr, ok := asLayer(layer)
+		if !ok {
+			fmt.Fprintf(w, "%s\n", escapeText(layer.Error()))
+			continue
+		}
+
+		fmt.Fprintf(w, "%s\n", escapeText(layerText(flooErr)))
+		if id := idOf(flooErr); id != (ErrorID{}) {
+			fmt.Fprintf(w, "    id: %s\n", id)
+		}
+		if op := opOf(flooErr); op != "" {
+			fmt.Fprintf(w, "    op: %s\n", escapeLine(op))
+		}
+		if code := flooErr.Code(); code != "" {
+			fmt.Fprintf(w, "    code: %s\n", escapeLine(code.String()))
+		}
+		if message := flooErr.Message(); message != "" && message != layerText(flooErr) {
+			fmt.Fprintf(w, "    message: %s\n", escapeLine(message))
+		}
+		if public := publicOf(flooErr); public != "" {
+			fmt.Fprintf(w, "    public: %s\n", escapeLine(public))
+		}
+		if context := flooErr.Context(); len(context) > 0 {
+			io.WriteString(w, "    context:\n")
+			for _, key := range sortedKeys(context) {
+				fmt.Fprintf(w, "        %s: %s\n", escapeLine(key), escapeLine(fmt.Sprint(context[key])))
+			}
+		}
+		if sdc := flooErr.SDC(); len(sdc) > 0 {
+			io.WriteString(w, "    sdc:\n")
+			for _, key := range sortedKeys(sdc) {
+				fmt.Fprintf(w, "        %s: %s\n", escapeLine(key), escapeLine(sdc[key]))
+			}
+		}
+		if stack := flooErr.StackTrace(); len(stack) > 0 {
+			io.WriteString(w, "    stack:\n")
+			for _, frame := range stack {
+				fmt.Fprintf(w, "        %s\n            %s:%d\n", frame.Function, frame.File, frame.Line)
+			}
+		}
+	}
+}
+
+// layerText returns the message of a single layer, without its causes.
+func layerText(flooErr FlooErr) string {
+	if e, ok := flooErr.(*err); ok {
+		return e.errMessage
+	}
+	if message := flooErr.Message(); message != "" {
+		return message
+	}
+	return flooErr.Error()
+}
+
+// jsonError is the JSON representation of one layer of the chain.
+type jsonError struct {
+	Error   string            `json:"error"`
+	ID      string            `json:"id,omitempty"`
+	Time    *time.Time        `json:"time,omitempty"`
+	Op      string            `json:"op,omitempty"`
+	Code    string            `json:"code,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Public  string            `json:"public_message,omitempty"`
+	Context map[string]any    `json:"context,omitempty"`
+	SDC     map[string]string `json:"sdc,omitempty"`
+	Stack   []jsonFrame       `json:"stack,omitempty"`
+	Cause   *jsonError        `json:"cause,omitempty"`
+}
+
+type jsonFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// MarshalJSON encodes the error chain as nested objects, each layer with
+// its error text, code, message, context, SDC, stack and cause. Context
+// values that cannot be encoded are written with fmt's %v.
+func (e *err) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSON(e))
+}
+
+func toJSON(err error) *jsonError {
+	var root, last *jsonError
+	for _, layer := range UnwrapChain(err) {
+		current := &jsonError{Error: layer.Error()}
+		if flooErr, ok := asLayer(layer); ok {
+			current.Error = layerText(flooErr)
+			current.ID = idOf(flooErr).String()
+			if built := timeOf(flooErr); !built.IsZero() {
+				current.Time = &built
+			}
+			current.Op = opOf(flooErr)
+			current.Code = flooErr.Code().String()
+			current.Message = flooErr.Message()
+			current.Public = publicOf(flooErr)
+			current.Context = encodableContext(flooErr.Context())
+			current.SDC = flooErr.SDC()
+			for _, frame := range flooErr.StackTrace() {
+				current.Stack = append(current.Stack, jsonFrame(frame))
+			}
+		}
+		if root == nil {
+			root = current
+		} else {
+			last.Cause = current
+		}
+		last = current
+	}
+	return root
+}
+
+// DecodeJSON decodes an error chain encoded by MarshalJSON. FlooErr layers
+// come back as FlooErrs with their code, messages, op, ID, build time,
+// context, SDC and stack; other layers keep their error text. Context
+// numbers are decoded as json.Number, which GetContextAs and Lookup convert.
+// Layers that carry nothing but their error text are decoded as foreign
+// errors.
+func DecodeJSON(data []byte) (error, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var root jsonError
+	if decodeErr := decoder.Decode(&root); decodeErr != nil {
+		return nil, decodeErr
+	}
+	return fromJSON(&root, 0)
+}
+
+// fromJSON rebuilds the layer j and its causes.
+func fromJSON(j *jsonError, depth int) (error, error) {
+	if depth >= DefaultMaxBinaryLayers {
+		return nil, fmt.Errorf("flooerr: error chain deeper than %d layers", DefaultMaxBinaryLayers)
+	}
+	var cause error
+	if j.Cause != nil {
+		var decodeErr error
+		if cause, decodeErr = fromJSON(j.Cause, depth+1); decodeErr != nil {
+			return nil, decodeErr
+		}
+	}
+
+	if j.ID == "" && j.Time == nil && j.Op == "" && j.Code == "" && j.Message == "" && j.Public == "" &&
+		len(j.Context) == 0 && len(j.SDC) == 0 && len(j.Stack) == 0 {
+		return &decodedError{message: j.Error, cause: cause}, nil
+	}
+
+	decoded := &err{
+		message:       j.Message,
+		errMessage:    j.Error,
+		publicMessage: j.Public,
+		code:          ErrCode(j.Code),
+		cause:         cause,
+		op:            j.Op,
+		context:       j.Context,
+		sdc:           j.SDC,
+	}
+	if id, ok := parseErrorID(j.ID); ok {
+		decoded.id = id
+	}
+	if j.Time != nil {
+		decoded.time = *j.Time
+	}
+	for _, frame := range j.Stack {
+		decoded.stackTrace = append(decoded.stackTrace, stacktrace(frame))
+	}
+	return decoded, nil
+}
+
+// encodableContext replaces values json cannot encode with their %v text.
+func encodableContext(context map[string]any) map[string]any {
+	if len(context) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(context))
+	for key, value := range context {
+		if _, err := json.Marshal(value); err != nil {
+			value = fmt.Sprintf("%v", value)
+		}
+		out[key] = value
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
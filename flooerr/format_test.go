@@ -0,0 +1,176 @@
+package flooerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErr_Format_Simple(t *testing.T) {
+	err := Message("wrapped").Error(errors.New("original"), "wrapped")
+
+	if s := fmt.Sprintf("%v", err); s != "wrapped; caused by: original" {
+		t.Errorf("Unexpected %%v output '%s'", s)
+	}
+
+	if s := fmt.Sprintf("%s", err); s != err.Error() {
+		t.Errorf("Unexpected %%s output '%s'", s)
+	}
+
+	if s := fmt.Sprintf("%q", err); s != `"wrapped; caused by: original"` {
+		t.Errorf("Unexpected %%q output '%s'", s)
+	}
+}
+
+func TestErr_Format_Detail(t *testing.T) {
+	inner := Message("find failed").
+		WithCode("NOT_FOUND").
+		WithContext("table", "users").
+		WithStackTrace(false).
+		Error(errors.New("sql: no rows"), "find failed")
+	outer := Message("get user failed").
+		WithCode("USER_ERR").
+		WithContext("id", 42).
+		WithSDC("request_id", "req_1").
+		WithStackTrace(false).
+		Error(inner, "get user failed")
+
+	expected := fmt.Sprintf(`get user failed
+    id: %s
+    code: USER_ERR
+    context:
+        id: 42
+    sdc:
+        request_id: req_1
+caused by: find failed
+    id: %s
+    code: NOT_FOUND
+    context:
+        table: users
+caused by: sql: no rows
+`, idOf(outer), idOf(inner))
+	if actual := fmt.Sprintf("%+v", outer); actual != expected {
+		t.Errorf("Unexpected %%+v output:\n%s", actual)
+	}
+}
+
+func TestErr_Format_DetailStack(t *testing.T) {
+	err := Message("m").WithMessage("shown").Error(nil, "fallback")
+
+	detail := fmt.Sprintf("%+v", err)
+	if !strings.Contains(detail, "    stack:\n") {
+		t.Errorf("Expected stack section, got:\n%s", detail)
+	}
+
+	if !strings.Contains(detail, "TestErr_Format_DetailStack") {
+		t.Errorf("Expected calling test in stack, got:\n%s", detail)
+	}
+}
+
+func TestErr_MarshalJSON(t *testing.T) {
+	inner := Message("find failed").
+		WithCode("NOT_FOUND").
+		WithContext("ch", make(chan int)).
+		WithStackTrace(false).
+		Error(errors.New("sql: no rows"), "find failed")
+	outer := Message("get user failed").
+		WithCode("USER_ERR").
+		WithContext("id", 42).
+		WithSDC("request_id", "req_1").
+		Error(inner, "get user failed")
+
+	data, err := json.Marshal(outer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if decoded["code"] != "USER_ERR" || decoded["error"] != "get user failed" {
+		t.Errorf("Unexpected top layer %v", decoded)
+	}
+
+	if _, ok := decoded["stack"].([]any); !ok {
+		t.Error("Expected stack frames in JSON")
+	}
+
+	cause := decoded["cause"].(map[string]any)
+	if cause["code"] != "NOT_FOUND" {
+		t.Errorf("Unexpected cause %v", cause)
+	}
+
+	if _, ok := cause["context"].(map[string]any)["ch"].(string); !ok {
+		t.Error("Expected unencodable context value to be rendered as text")
+	}
+
+	root := cause["cause"].(map[string]any)
+	if root["error"] != "sql: no rows" || root["code"] != nil {
+		t.Errorf("Unexpected root cause %v", root)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	inner := Op("repo.Find").
+		WithCode("NOT_FOUND").
+		WithContext("id", 42).
+		WithSDC("db", "primary").
+		Error(fmt.Errorf("query: %w", errors.New("sql: no rows")), "find failed")
+	original := Message("shown").Wrap(inner, "get user failed")
+
+	data, _ := json.Marshal(original)
+	decoded, err := DecodeJSON(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if decoded.Error() != original.Error() {
+		t.Errorf("Expected '%s', got '%s'", original.Error(), decoded.Error())
+	}
+
+	chain := ParseChain(decoded)
+	if len(chain) != 4 || !chain[1].IsFlooErr || chain[2].IsFlooErr || chain[3].IsFlooErr {
+		t.Fatalf("Unexpected chain %+v", chain)
+	}
+
+	originalInfo := Parse(inner)
+	if chain[1].ID != originalInfo.ID || !chain[1].Time.Equal(originalInfo.Time) || chain[1].Op != "repo.Find" {
+		t.Errorf("Expected ID, time and op to round trip, got %+v", chain[1])
+	}
+
+	if value, ok := Lookup(decoded, NewKey[int]("id")); !ok || value != 42 {
+		t.Errorf("Expected context id 42, got %v %v", value, ok)
+	}
+
+	if chain[1].SDC["db"] != "primary" {
+		t.Errorf("Unexpected SDC %v", chain[1].SDC)
+	}
+
+	if len(chain[1].StackTrace) == 0 || chain[1].StackTrace[0] != originalInfo.StackTrace[0] {
+		t.Error("Expected stack frames to round trip")
+	}
+}
+
+func TestDecodeJSON_Invalid(t *testing.T) {
+	if _, err := DecodeJSON([]byte("{")); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestParseErrorID(t *testing.T) {
+	id := idOf(Error("test"))
+	parsed, ok := parseErrorID(id.String())
+	if !ok || parsed != id {
+		t.Errorf("Expected %s, got %s", id, parsed)
+	}
+
+	for _, invalid := range []string{"", "8ZZZZZZZZZZZZZZZZZZZZZZZZZ", "0000000000000000000000000U"} {
+		if _, ok := parseErrorID(invalid); ok {
+			t.Errorf("Expected '%s' to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package flooerr
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+type hook struct {
+	id   uint64
+	fn   func(FlooErr)
+	rate float64
+}
+
+// HookOption configures a hook registered with OnBuild.
+type HookOption func(*hook)
+
+// WithSampleRate runs the hook for roughly rate of the built errors, where
+// rate is between 0 and 1. The default runs it for every error.
+func WithSampleRate(rate float64) HookOption {
+	return func(h *hook) {
+		h.rate = rate
+	}
+}
+
+var (
+	hooksMu sync.Mutex
+	hooks   atomic.Pointer[[]*hook]
+	hookID  uint64
+
+	// hookRand is replaced in tests.
+	hookRand = rand.Float64
+
+	// runningHooks counts the errors whose hooks are running on any
+	// goroutine, so builds only look for a reentrant call while one is.
+	runningHooks atomic.Int32
+)
+
+// OnBuild registers fn to run whenever a FlooErr is built, and returns a
+// function that unregisters it. Hooks run synchronously on the building
+// goroutine in registration order; a hook that panics is recovered and
+// reported on stderr without affecting the other hooks or the caller.
+// Sentinels and decoded errors do not trigger hooks, nor do errors that
+// wrap an error whose hooks are still running, so a hook may wrap the error
+// it receives, on any goroutine. Hooks must not build other errors: those
+// run the hooks again and recurse.
+func OnBuild(fn func(FlooErr), opts ...HookOption) (unregister func()) {
+	h := &hook{fn: fn, rate: 1}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	hooksMu.Lock()
+	hookID++
+	h.id = hookID
+	var current []*hook
+	if registered := hooks.Load(); registered != nil {
+		current = *registered
+	}
+	updated := append(slices.Clip(current), h)
+	hooks.Store(&updated)
+	hooksMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { removeHook(h.id) })
+	}
+}
+
+func removeHook(id uint64) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+
+	registered := hooks.Load()
+	if registered == nil {
+		return
+	}
+	updated := slices.DeleteFunc(slices.Clone(*registered), func(h *hook) bool {
+		return h.id == id
+	})
+	if len(updated) == 0 {
+		hooks.Store(nil)
+		return
+	}
+	hooks.Store(&updated)
+}
+
+// runHooks passes a newly built error to the registered hooks.
+func runHooks(built *err) {
+	registered := hooks.Load()
+	if registered == nil || wrapsHooked(built.cause) {
+		return
+	}
+
+	built.hooking.Store(true)
+	runningHooks.Add(1)
+	defer func() {
+		runningHooks.Add(-1)
+		built.hooking.Store(false)
+	}()
+	for _, h := range *registered {
+		if h.rate < 1 && (h.rate <= 0 || hookRand() >= h.rate) {
+			continue
+		}
+		h.run(built)
+	}
+}
+
+// wrapsHooked reports whether cause is, or wraps, an error whose hooks are
+// running.
+func wrapsHooked(cause error) bool {
+	if runningHooks.Load() == 0 {
+		return false
+	}
+	for layer := range All(cause) {
+		if e, ok := layer.(*err); ok && e.hooking.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *hook) run(flooErr FlooErr) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			fmt.Fprintf(os.Stderr, "flooerr: build hook panicked: %v\n", recovered)
+		}
+	}()
+	h.fn(flooErr)
+}
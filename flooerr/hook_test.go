@@ -0,0 +1,156 @@
+package flooerr
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOnBuild(t *testing.T) {
+	var order []string
+	unregisterFirst := OnBuild(func(e FlooErr) { order = append(order, "first:"+e.Code().String()) })
+	unregisterSecond := OnBuild(func(e FlooErr) { order = append(order, "second:"+e.Code().String()) })
+
+	_ = Code("TEST").Error(nil, "test")
+	unregisterFirst()
+	unregisterFirst()
+	_ = Code("AFTER").Error(nil, "test")
+	unregisterSecond()
+	_ = Error("ignored")
+
+	expected := []string{"first:TEST", "second:TEST", "second:AFTER"}
+	if len(order) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, order)
+			break
+		}
+	}
+}
+
+func TestOnBuild_SkipsSentinelsAndDecoded(t *testing.T) {
+	count := 0
+	defer OnBuild(func(FlooErr) { count++ })()
+
+	data, _ := MarshalBinary(Sentinel("X", "x"))
+	_, _ = UnmarshalBinary(data)
+
+	if count != 0 {
+		t.Errorf("Expected no hook calls, got %d", count)
+	}
+}
+
+func TestOnBuild_PanicIsolation(t *testing.T) {
+	stderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stderr = stderr }()
+
+	called := false
+	defer OnBuild(func(FlooErr) { panic("boom") })()
+	defer OnBuild(func(FlooErr) { called = true })()
+
+	if err := Error("test"); err == nil {
+		t.Fatal("Expected an error despite the panicking hook")
+	}
+
+	if !called {
+		t.Error("Expected later hooks to run after a panic")
+	}
+}
+
+func TestOnBuild_Reentrant(t *testing.T) {
+	var codes []string
+	defer OnBuild(func(e FlooErr) {
+		codes = append(codes, e.Code().String())
+		_ = Code("INSIDE").Wrap(e, "built by the hook")
+	})()
+
+	_ = Code("OUTSIDE").Error(nil, "test")
+	_ = Code("AGAIN").Error(nil, "test")
+
+	if len(codes) != 2 || codes[0] != "OUTSIDE" || codes[1] != "AGAIN" {
+		t.Errorf("Expected hooks to skip errors built inside a hook, got %v", codes)
+	}
+}
+
+func TestOnBuild_ReentrantOtherGoroutine(t *testing.T) {
+	var calls atomic.Int32
+	defer OnBuild(func(e FlooErr) {
+		calls.Add(1)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = Code("INSIDE").Wrap(e, "built for the hook")
+		}()
+		<-done
+	})()
+
+	_ = Code("OUTSIDE").Error(nil, "test")
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected wrapping on another goroutine to skip hooks, got %d calls", calls.Load())
+	}
+}
+
+func TestOnBuild_OtherGoroutines(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	var other atomic.Bool
+	defer OnBuild(func(e FlooErr) {
+		switch e.Code() {
+		case "BLOCKING":
+			close(entered)
+			<-release
+		case "OTHER":
+			other.Store(true)
+		}
+	})()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = Code("BLOCKING").Error(nil, "test")
+	}()
+	<-entered
+	_ = Code("OTHER").Error(nil, "test")
+	close(release)
+	<-done
+
+	if !other.Load() {
+		t.Error("Expected a hook running elsewhere not to suppress hooks on this goroutine")
+	}
+}
+
+func TestOnBuild_SampleRate(t *testing.T) {
+	defer func(original func() float64) { hookRand = original }(hookRand)
+	values := []float64{0.05, 0.5, 0.09}
+	hookRand = func() float64 {
+		v := values[0]
+		values = values[1:]
+		return v
+	}
+
+	sampled, never := 0, 0
+	defer OnBuild(func(FlooErr) { sampled++ }, WithSampleRate(0.1))()
+	defer OnBuild(func(FlooErr) { never++ }, WithSampleRate(0))()
+
+	for i := 0; i < 3; i++ {
+		_ = Error("test")
+	}
+
+	if sampled != 2 || never != 0 {
+		t.Errorf("Expected 2 sampled and 0 disabled calls, got %d and %d", sampled, never)
+	}
+}
+
+func TestOnBuild_ReceivesBuiltError(t *testing.T) {
+	var seen FlooErr
+	defer OnBuild(func(e FlooErr) { seen = e })()
+
+	err := Wrap(errors.New("cause"), "wrapped")
+	if seen == nil || seen.Error() != err.Error() {
+		t.Errorf("Expected the hook to see %v, got %v", err, seen)
+	}
+}
@@ -0,0 +1,210 @@
+// Package httpclient turns failed HTTP calls into FlooErrs.
+//
+// Transport failures (DNS, refused connections, TLS, timeouts) and non-2xx
+// responses come back as FlooErrs carrying the method, the URL with its
+// query redacted, the status and the attempt number in their context, and
+// flooerr.KeyRetryable telling whether the call is worth retrying:
+//
+//	resp, err := httpclient.Do(client, req)
+//	if err != nil {
+//		if flooerr.IsRetryable(err) {
+//			...
+//		}
+//		return flooerr.Wrap(err, "fetch user failed")
+//	}
+//
+// Responses with an application/problem+json body are decoded back into the
+// code, message and context of the remote error, so the error reads like the
+// remote one; other bodies are captured up to a size limit.
+package httpclient
+
+import (
+	"context"
+	"core-common-go/flooerr"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+)
+
+// Codes of the errors returned by this package. Errors for non-2xx
+// responses take the code of their problem+json body when it has one.
+const (
+	// CodeTransport is the code of transport failures not covered below,
+	// such as reset connections.
+	CodeTransport flooerr.ErrCode = "HTTP_TRANSPORT"
+	// CodeDNS is the code of failed host lookups.
+	CodeDNS flooerr.ErrCode = "HTTP_DNS"
+	// CodeConnectionRefused is the code of refused connections.
+	CodeConnectionRefused flooerr.ErrCode = "HTTP_CONNECTION_REFUSED"
+	// CodeTLS is the code of failed TLS handshakes and certificate checks.
+	CodeTLS flooerr.ErrCode = "HTTP_TLS"
+	// CodeTimeout is the code of calls that timed out.
+	CodeTimeout flooerr.ErrCode = "HTTP_TIMEOUT"
+	// CodeCanceled is the code of calls whose context was canceled.
+	CodeCanceled flooerr.ErrCode = "HTTP_CANCELED"
+	// CodeStatus is the code of non-2xx responses.
+	CodeStatus flooerr.ErrCode = "HTTP_STATUS"
+)
+
+// Context keys of the errors returned by this package.
+var (
+	KeyMethod  = flooerr.NewKey[string]("http_method")
+	KeyURL     = flooerr.NewKey[string]("http_url")
+	KeyStatus  = flooerr.NewKey[int]("http_status")
+	KeyAttempt = flooerr.NewKey[int]("attempt")
+	// KeyBody holds the captured body of a response that is not
+	// problem+json.
+	KeyBody = flooerr.NewKey[string]("http_body")
+	// KeyBodyTruncated is set when the body was longer than the limit.
+	KeyBodyTruncated = flooerr.NewKey[bool]("http_body_truncated")
+	// KeyRemoteID holds the error ID reported by the server, from the
+	// problem+json body or the flooerr.HeaderErrorID header.
+	KeyRemoteID = flooerr.NewKey[string]("remote_error_id")
+)
+
+// DefaultMaxBodyBytes is the body capture limit used when none is set.
+const DefaultMaxBodyBytes = 4 << 10
+
+// RetryableStatus reports whether a response with status is worth retrying:
+// 408, 425, 429, 500, 502, 503 and 504 are.
+func RetryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+type attemptKey struct{}
+
+// WithAttempt records the attempt number of the calls made with ctx, for
+// retry loops. Calls without it are attempt 1.
+func WithAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+func attemptOf(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+// Transport is an http.RoundTripper that returns FlooErrs for transport
+// failures. Responses are returned unchanged whatever their status, so
+// http.Client still follows redirects; check them with CheckResponse.
+type Transport struct {
+	// Base sends the requests; http.DefaultTransport when nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, transportError(req, err, 0)
+	}
+	return resp, nil
+}
+
+// CheckResponse returns nil for a 2xx response. Otherwise it reads up to
+// DefaultMaxBodyBytes of the body, closes it, and returns a FlooErr with
+// CodeStatus describing the response, like Do does.
+func CheckResponse(resp *http.Response) error {
+	return responseError(resp.Request, resp, 0, 1)
+}
+
+// Do sends req with client, or http.DefaultClient when nil, and returns
+// FlooErrs for transport failures and, like CheckResponse, for non-2xx
+// responses. Transport failures are returned without the
+// *url.Error that http.Client wraps them in, since its text repeats the URL
+// with the query.
+func Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		if flooerr.IsFlooErr(err) {
+			return nil, err
+		}
+		return nil, transportError(req, err, 1)
+	}
+	if err := responseError(req, resp, 0, 1); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// transportError classifies a failure to get a response. Stack traces start
+// skip frames above its caller.
+func transportError(req *http.Request, cause error, skip int) error {
+	code, retryable := classify(cause)
+	return withRequest(flooerr.Code(code), req, retryable).
+		BuildSkip(skip, cause, req.Method+" "+RedactURL(req.URL))
+}
+
+// classify returns the code of a transport failure and whether it is worth
+// retrying.
+func classify(err error) (flooerr.ErrCode, bool) {
+	var (
+		dnsErr       *net.DNSError
+		netErr       net.Error
+		certErr      *tls.CertificateVerificationError
+		alertErr     tls.AlertError
+		recordErr    tls.RecordHeaderError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled, false
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return CodeTimeout, true
+	case errors.As(err, &dnsErr):
+		return CodeDNS, !dnsErr.IsNotFound
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return CodeConnectionRefused, true
+	case errors.As(err, &certErr), errors.As(err, &alertErr), errors.As(err, &recordErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return CodeTLS, false
+	}
+	return CodeTransport, true
+}
+
+// RedactURL returns u without its user password and fragment, and with the
+// values of its query replaced by REDACTED, so it can be logged.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redacted := *u
+	redacted.Fragment, redacted.RawFragment = "", ""
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		for key, values := range query {
+			for i := range values {
+				values[i] = "REDACTED"
+			}
+			query[key] = values
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.Redacted()
+}
@@ -0,0 +1,152 @@
+package httpclient
+
+import (
+	"bytes"
+	"core-common-go/flooerr"
+	"core-common-go/flooerr/internal"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// withRequest adds the request context every error of this package carries
+// to props.
+func withRequest(props *internal.ErrProps, req *http.Request, retryable bool) *internal.ErrProps {
+	flooerr.With(props, KeyMethod, req.Method)
+	flooerr.With(props, KeyURL, RedactURL(req.URL))
+	flooerr.With(props, KeyAttempt, attemptOf(req.Context()))
+	return flooerr.With(props, flooerr.KeyRetryable, retryable)
+}
+
+// responseError returns nil for 2xx responses. Otherwise it reads up to
+// limit bytes of the body, closes it, and returns the FlooErr describing the
+// response; stack traces start skip frames above its caller.
+func responseError(req *http.Request, resp *http.Response, limit int64, skip int) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+	body, truncated := readBody(resp.Body, limit)
+	resp.Body.Close()
+
+	// The remote context goes first, so it cannot replace the request
+	// context.
+	props := flooerr.Code(CodeStatus)
+	if id := resp.Header.Get(flooerr.HeaderErrorID); id != "" {
+		flooerr.With(props, KeyRemoteID, id)
+	}
+	if p, ok := decodeProblem(resp.Header, body, truncated); ok {
+		p.apply(props)
+	} else {
+		if len(body) > 0 {
+			flooerr.With(props, KeyBody, strings.ToValidUTF8(string(body), "\uFFFD"))
+		}
+		if truncated {
+			flooerr.With(props, KeyBodyTruncated, true)
+		}
+	}
+	message := fmt.Sprintf("%s %s: %s", req.Method, RedactURL(req.URL), statusText(resp))
+	props = withRequest(props, req, RetryableStatus(resp.StatusCode))
+	return flooerr.With(props, KeyStatus, resp.StatusCode).BuildSkip(skip, nil, message)
+}
+
+// statusText returns the status line of resp, such as "404 Not Found".
+func statusText(resp *http.Response) string {
+	if resp.Status != "" {
+		return resp.Status
+	}
+	return fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+}
+
+// readBody reads up to limit bytes of body and reports whether there was
+// more.
+func readBody(body io.Reader, limit int64) ([]byte, bool) {
+	data, _ := io.ReadAll(io.LimitReader(body, limit+1))
+	if int64(len(data)) > limit {
+		return data[:limit], true
+	}
+	return data, false
+}
+
+// problem is an RFC 9457 problem details body. The code, id and context
+// members are the extensions FlooErr renderers write; other extension
+// members are added to the context.
+type problem struct {
+	Type     string
+	Title    string
+	Detail   string
+	Instance string
+	Code     string
+	ID       string
+	Context  map[string]any
+}
+
+// problemMembers are the members decoded into the fields of problem.
+var problemMembers = map[string]bool{
+	"type": true, "title": true, "status": true, "detail": true, "instance": true,
+	"code": true, "id": true, "context": true,
+}
+
+// decodeProblem decodes a complete application/problem+json body.
+func decodeProblem(header http.Header, body []byte, truncated bool) (*problem, bool) {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || mediaType != "application/problem+json" || truncated {
+		return nil, false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var members map[string]any
+	if err := decoder.Decode(&members); err != nil {
+		return nil, false
+	}
+
+	p := &problem{Context: map[string]any{}}
+	for name, target := range map[string]*string{
+		"type": &p.Type, "title": &p.Title, "detail": &p.Detail, "instance": &p.Instance,
+		"code": &p.Code, "id": &p.ID,
+	} {
+		*target, _ = members[name].(string)
+	}
+	if context, ok := members["context"].(map[string]any); ok {
+		for key, value := range context {
+			p.Context[key] = value
+		}
+	}
+	for name, value := range members {
+		if !problemMembers[name] {
+			p.Context[name] = value
+		}
+	}
+	return p, true
+}
+
+// apply sets the code, message and context of the remote error on props.
+// The message is the detail of the problem, or its title.
+func (p *problem) apply(props *internal.ErrProps) {
+	if p.Code != "" {
+		props.WithCode(p.Code)
+	}
+	if message := p.Detail; message != "" {
+		props.WithMessage(message)
+	} else if p.Title != "" {
+		props.WithMessage(p.Title)
+	}
+	if p.ID != "" {
+		flooerr.With(props, KeyRemoteID, p.ID)
+	}
+	if p.Type != "" && p.Type != "about:blank" {
+		props.WithContext("problem_type", p.Type)
+	}
+	if p.Instance != "" {
+		props.WithContext("problem_instance", p.Instance)
+	}
+	for key, value := range p.Context {
+		props.WithContext(key, value)
+	}
+}
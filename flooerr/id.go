@@ -0,0 +1,213 @@
+package flooerr
+
+import (
+	"encoding/binary"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrorID is a 128-bit identifier assigned to every built FlooErr. Like a
+// ULID it starts with the build time in milliseconds followed by 80 random
+// bits, and its string form is 26 characters of Crockford base32 that sort
+// in creation order.
+type ErrorID [16]byte
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// String returns the 26-character Crockford base32 form of the ID, or ""
+// for the zero ID.
+func (id ErrorID) String() string {
+	if id == (ErrorID{}) {
+		return ""
+	}
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// parseErrorID parses the string form of an ErrorID.
+func parseErrorID(s string) (ErrorID, bool) {
+	if len(s) != 26 || s[0] > '7' {
+		return ErrorID{}, false
+	}
+	var hi, lo uint64
+	for i := 0; i < len(s); i++ {
+		v := strings.IndexByte(crockford, s[i])
+		if v < 0 {
+			return ErrorID{}, false
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	var id ErrorID
+	binary.BigEndian.PutUint64(id[:8], hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+	return id, true
+}
+
+var idState struct {
+	sync.Mutex
+	ms     uint64
+	random [10]byte
+}
+
+// newID returns an ID for an error built at now. IDs built within the same
+// millisecond increment the random part so they still sort in order.
+func newID(now time.Time) ErrorID {
+	ms := uint64(now.UnixMilli())
+
+	idState.Lock()
+	if ms > idState.ms {
+		idState.ms = ms
+		binary.BigEndian.PutUint64(idState.random[:8], rand.Uint64())
+		binary.BigEndian.PutUint16(idState.random[8:], uint16(rand.Uint32()))
+	} else {
+		for i := len(idState.random) - 1; i >= 0; i-- {
+			idState.random[i]++
+			if idState.random[i] != 0 {
+				break
+			}
+		}
+	}
+	var id ErrorID
+	binary.BigEndian.PutUint64(id[:8], idState.ms<<16)
+	copy(id[6:], idState.random[:])
+	idState.Unlock()
+	return id
+}
+
+// ID returns the unique ID assigned when the error was built.
+func (e *err) ID() ErrorID {
+	return e.id
+}
+
+// Time returns when the error was built.
+func (e *err) Time() time.Time {
+	return e.time
+}
+
+// GetID returns the ID of the outermost FlooErr in the chain, or "".
+func GetID(err error) string {
+	for flooErr := range FlooErrs(err) {
+		if id := idOf(flooErr); id != (ErrorID{}) {
+			return id.String()
+		}
+	}
+	return ""
+}
+
+// idOf returns the ID of a single chain element, if it has one.
+func idOf(err error) ErrorID {
+	if layer, ok := err.(interface{ ID() ErrorID }); ok {
+		return layer.ID()
+	}
+	return ErrorID{}
+}
+
+// timeOf returns the build time of a single chain element, if it has one.
+func timeOf(err error) time.Time {
+	if layer, ok := err.(interface{ Time() time.Time }); ok {
+		return layer.Time()
+	}
+	return time.Time{}
+}
+
+// HeaderErrorID is the response header that carries the error ID.
+const HeaderErrorID = "X-Error-Id"
+
+// SetIDHeader sets HeaderErrorID to the ID of err, so HTTP renderers can
+// echo it to clients who later report the failure. It does nothing when err
+// has no ID.
+func SetIDHeader(header http.Header, err error) {
+	if id := GetID(err); id != "" {
+		header.Set(HeaderErrorID, id)
+	}
+}
+
+// SDC keys used for process metadata.
+const (
+	SDCService  = "service"
+	SDCVersion  = "version"
+	SDCRevision = "vcs_revision"
+	SDCHostname = "hostname"
+)
+
+// ProcessInfo describes the running process. Its non-empty fields are
+// attached to the SDC of every built error once registered with
+// SetProcessInfo.
+type ProcessInfo struct {
+	Service  string
+	Version  string
+	Revision string
+	Hostname string
+}
+
+// DetectProcessInfo returns a ProcessInfo for service with the module
+// version and VCS revision from debug.ReadBuildInfo and the hostname.
+func DetectProcessInfo(service string) ProcessInfo {
+	info := ProcessInfo{Service: service}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			if setting.Key == "vcs.revision" {
+				info.Revision = setting.Value
+			}
+		}
+	}
+	info.Hostname, _ = os.Hostname()
+	return info
+}
+
+var processSDC atomic.Pointer[map[string]string]
+
+// SetProcessInfo attaches info to the SDC of every error built afterwards.
+// Values set explicitly with WithSDC take precedence. The zero ProcessInfo
+// stops attaching metadata.
+func SetProcessInfo(info ProcessInfo) {
+	sdc := make(map[string]string)
+	for key, value := range map[string]string{
+		SDCService:  info.Service,
+		SDCVersion:  info.Version,
+		SDCRevision: info.Revision,
+		SDCHostname: info.Hostname,
+	} {
+		if value != "" {
+			sdc[key] = value
+		}
+	}
+	if len(sdc) == 0 {
+		processSDC.Store(nil)
+		return
+	}
+	processSDC.Store(&sdc)
+}
+
+// withProcessSDC returns sdc with the registered process metadata added. It
+// copies sdc rather than writing to it, since the map belongs to a builder
+// that may be built again, possibly from other goroutines.
+func withProcessSDC(sdc map[string]string) map[string]string {
+	process := processSDC.Load()
+	if process == nil {
+		return sdc
+	}
+	merged := make(map[string]string, len(sdc)+len(*process))
+	maps.Copy(merged, *process)
+	maps.Copy(merged, sdc)
+	return merged
+}
@@ -0,0 +1,163 @@
+package flooerr
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestErrorID(t *testing.T) {
+	before := time.Now()
+	err := Error("test")
+	after := time.Now()
+
+	info := Parse(err)
+	if len(info.ID) != 26 {
+		t.Errorf("Expected a 26-character ID, got '%s'", info.ID)
+	}
+
+	if info.Time.Before(before) || info.Time.After(after) {
+		t.Errorf("Expected build time between %v and %v, got %v", before, after, info.Time)
+	}
+
+	if GetID(err) != info.ID {
+		t.Errorf("Expected GetID '%s', got '%s'", info.ID, GetID(err))
+	}
+}
+
+func TestErrorID_Sortable(t *testing.T) {
+	previous := ""
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := GetID(Error("test"))
+		if seen[id] {
+			t.Fatalf("Duplicate ID '%s'", id)
+		}
+		if id <= previous {
+			t.Fatalf("Expected '%s' to sort after '%s'", id, previous)
+		}
+		seen[id] = true
+		previous = id
+	}
+}
+
+func TestErrorID_String(t *testing.T) {
+	if (ErrorID{}).String() != "" {
+		t.Error("Expected empty string for the zero ID")
+	}
+
+	id := ErrorID{15: 1}
+	if id.String() != "00000000000000000000000001" {
+		t.Errorf("Unexpected encoding '%s'", id.String())
+	}
+
+	id = ErrorID{0: 0xff, 1: 0xff, 2: 0xff, 3: 0xff, 4: 0xff, 5: 0xff, 6: 0xff, 7: 0xff, 8: 0xff, 9: 0xff, 10: 0xff, 11: 0xff, 12: 0xff, 13: 0xff, 14: 0xff, 15: 0xff}
+	if id.String() != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" {
+		t.Errorf("Unexpected encoding '%s'", id.String())
+	}
+}
+
+func TestGetID_Outermost(t *testing.T) {
+	inner := Error("inner")
+	outer := Wrap(inner, "outer")
+
+	if GetID(outer) == GetID(inner) || GetID(outer) != Parse(outer).ID {
+		t.Error("Expected GetID to return the outermost ID")
+	}
+
+	if GetID(errors.New("plain")) != "" || GetID(Sentinel("X", "x")) != "" {
+		t.Error("Expected no ID for foreign errors and sentinels")
+	}
+}
+
+func TestSetIDHeader(t *testing.T) {
+	err := Error("test")
+	header := http.Header{}
+	SetIDHeader(header, err)
+
+	if header.Get(HeaderErrorID) != GetID(err) {
+		t.Errorf("Expected header '%s', got '%s'", GetID(err), header.Get(HeaderErrorID))
+	}
+
+	header = http.Header{}
+	SetIDHeader(header, errors.New("plain"))
+	if len(header) != 0 {
+		t.Error("Expected no header for errors without an ID")
+	}
+}
+
+func TestSetProcessInfo(t *testing.T) {
+	SetProcessInfo(ProcessInfo{Service: "billing", Version: "v1.2.3", Hostname: "host-1"})
+	defer SetProcessInfo(ProcessInfo{})
+
+	err := Message("test").WithSDC(SDCHostname, "explicit").Error(nil, "test")
+	sdc := GetSDC(err)
+
+	if sdc[SDCService] != "billing" || sdc[SDCVersion] != "v1.2.3" {
+		t.Errorf("Expected process metadata in SDC, got %v", sdc)
+	}
+
+	if _, exists := sdc[SDCRevision]; exists {
+		t.Error("Expected empty fields to be skipped")
+	}
+
+	if sdc[SDCHostname] != "explicit" {
+		t.Errorf("Expected explicit SDC to win, got '%s'", sdc[SDCHostname])
+	}
+
+	SetProcessInfo(ProcessInfo{})
+	if len(GetSDC(Error("test"))) != 0 {
+		t.Error("Expected the zero ProcessInfo to stop attaching metadata")
+	}
+}
+
+func TestSetProcessInfo_SharedBuilder(t *testing.T) {
+	SetProcessInfo(ProcessInfo{Service: "billing"})
+	defer SetProcessInfo(ProcessInfo{})
+
+	props := Message("test").WithSDC("request", "r-1")
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if sdc := GetSDC(props.Error(nil, "test")); sdc[SDCService] != "billing" {
+					t.Errorf("Expected process metadata in SDC, got %v", sdc)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	SetProcessInfo(ProcessInfo{})
+	if sdc := GetSDC(props.Error(nil, "test")); len(sdc) != 1 {
+		t.Errorf("Expected the builder SDC to be left alone, got %v", sdc)
+	}
+}
+
+func TestDetectProcessInfo(t *testing.T) {
+	info := DetectProcessInfo("billing")
+
+	if info.Service != "billing" {
+		t.Errorf("Expected service 'billing', got '%s'", info.Service)
+	}
+
+	if hostname, _ := os.Hostname(); info.Hostname != hostname {
+		t.Errorf("Expected hostname '%s', got '%s'", hostname, info.Hostname)
+	}
+}
+
+func TestErrorID_Binary(t *testing.T) {
+	err := Error("test")
+	data, _ := MarshalBinary(err)
+	decoded, _ := UnmarshalBinary(data)
+
+	if GetID(decoded) != GetID(err) || !Parse(decoded).Time.Equal(Parse(err).Time) {
+		t.Error("Expected ID and time to survive binary encoding")
+	}
+}
@@ -3,6 +3,9 @@ package internal
 import (
 	"fmt"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type Code string
@@ -11,22 +14,45 @@ func (code Code) String() string {
 	return string(code)
 }
 
+// CodeSeparator separates the segments of hierarchical codes, such as
+// "PAYMENT.CARD.DECLINED".
+const CodeSeparator = "."
+
+// Parent returns the code one level up: "PAYMENT.CARD" for
+// "PAYMENT.CARD.DECLINED", and "" for a top-level code.
+func (code Code) Parent() Code {
+	if i := strings.LastIndex(string(code), CodeSeparator); i >= 0 {
+		return code[:i]
+	}
+	return ""
+}
+
+// Under reports whether code is prefix or a descendant of it. Segments
+// match whole, so "PAYMENT.CARDS" is not under "PAYMENT.CARD". Every
+// non-empty code is under the empty prefix.
+func (code Code) Under(prefix Code) bool {
+	if prefix == "" {
+		return code != ""
+	}
+	return code == prefix || strings.HasPrefix(string(code), string(prefix)+CodeSeparator)
+}
+
 type ErrProps struct {
 	message        string
 	code           string
 	withStackTrace bool
 	context        map[string]any
 	sdc            map[string]string
+	op             string
+	publicMessage  string
+	inheritCode    bool
 }
 
+// create stays small enough to inline, so a builder chain that ends in
+// Build within the same function keeps its ErrProps on the stack. The
+// context and SDC maps are allocated on first use.
 func create() *ErrProps {
-	context := make(map[string]any)
-	sdc := make(map[string]string)
-	return &ErrProps{
-		context:        context,
-		sdc:            sdc,
-		withStackTrace: true,
-	}
+	return &ErrProps{withStackTrace: true}
 }
 
 // Create creates a new ErrProps instance
@@ -45,25 +71,78 @@ func (receiver *ErrProps) WithCode(code string) *ErrProps {
 	return receiver
 }
 
+// WithOp records the logical operation of the layer, such as
+// "user.Service.Get".
+func (receiver *ErrProps) WithOp(op string) *ErrProps {
+	receiver.op = op
+	return receiver
+}
+
+// WithPublicMessage sets a message that is safe to show to users, unlike
+// the error text which may carry internal detail.
+func (receiver *ErrProps) WithPublicMessage(message string) *ErrProps {
+	receiver.publicMessage = message
+	return receiver
+}
+
+// WithInheritCode controls whether a layer built without a code inherits
+// the effective code of its cause. A code set with WithCode always
+// overrides the cause's code.
+func (receiver *ErrProps) WithInheritCode(inherit bool) *ErrProps {
+	receiver.inheritCode = inherit
+	return receiver
+}
+
 func (receiver *ErrProps) WithStackTrace(enableStackTrace bool) *ErrProps {
 	receiver.withStackTrace = enableStackTrace
 	return receiver
 }
 
 func (receiver *ErrProps) WithContext(key string, value any) *ErrProps {
+	if receiver.context == nil {
+		receiver.context = make(map[string]any)
+	}
 	receiver.context[key] = value
 	return receiver
 }
 
 func (receiver *ErrProps) WithSDC(key string, value string) *ErrProps {
+	if receiver.sdc == nil {
+		receiver.sdc = make(map[string]string)
+	}
 	receiver.sdc[key] = value
 	return receiver
 }
 
+// Build creates the error. Its stack trace starts at the caller of Build.
 func (receiver *ErrProps) Build(cause error, message string) error {
+	return receiver.build(cause, message, buildSkip)
+}
+
+// BuildSkip is Build for helpers that build on behalf of their caller. The
+// stack trace starts at the helper's caller, with skip further frames
+// dropped.
+func (receiver *ErrProps) BuildSkip(skip int, cause error, message string) error {
+	return receiver.build(cause, message, helperSkip+skip)
+}
+
+// Frames skipped by runtime.Callers to reach the caller of Build, Error,
+// Wrap and the other building methods: Callers itself, callers or caller,
+// build and the method. Helpers calling BuildSkip add their own frame.
+const (
+	buildSkip  = 4
+	helperSkip = buildSkip + 1
+)
+
+func (receiver *ErrProps) build(cause error, message string, skip int) error {
 	var stackTracePTR []uintptr
 	if receiver.withStackTrace {
-		stackTracePTR = callers(4) // Skip Build, caller of Build, and runtime frames
+		switch StackMode(stackMode.Load()) {
+		case StackFull:
+			stackTracePTR = callers(skip)
+		case StackReturn:
+			stackTracePTR = caller(skip)
+		}
 	}
 
 	errMessage := message
@@ -73,15 +152,18 @@ func (receiver *ErrProps) Build(cause error, message string) error {
 
 	// Create error using BuildErr function which should be set by flooerr package
 	if buildErrFunc != nil {
-		return buildErrFunc(
-			receiver.message,
-			errMessage,
-			Code(receiver.code),
-			cause,
-			stackTracePTR,
-			receiver.context,
-			receiver.sdc,
-		)
+		return buildErrFunc(BuildArgs{
+			Message:       receiver.message,
+			ErrMessage:    errMessage,
+			Code:          Code(receiver.code),
+			Cause:         cause,
+			StackTracePTR: stackTracePTR,
+			Context:       receiver.context,
+			SDC:           receiver.sdc,
+			Op:            receiver.op,
+			PublicMessage: receiver.publicMessage,
+			InheritCode:   receiver.inheritCode,
+		})
 	}
 
 	// Fallback: return a simple error if builder is not set
@@ -95,31 +177,89 @@ func (receiver *ErrProps) Build(cause error, message string) error {
 // If cause is provided, it will be wrapped as the underlying error.
 // The message parameter is used as a fallback if no message was set via WithMessage().
 func (receiver *ErrProps) Error(cause error, message string) error {
-	return receiver.Build(cause, message)
+	return receiver.build(cause, message, buildSkip)
 }
 
 func (receiver *ErrProps) Errorf(format string, args ...any) error {
-	return receiver.Build(nil, fmt.Sprintf(format, args...))
+	return receiver.build(nil, fmt.Sprintf(format, args...), buildSkip)
 }
 
+// Wrap builds an error wrapping cause. A nil cause still produces a new
+// error (or panics in strict nil-wrap mode); use WrapIf to propagate nil.
 func (receiver *ErrProps) Wrap(cause error, message string) error {
-	return receiver.Build(cause, message)
+	CheckNilCause(cause, "ErrProps.Wrap")
+	return receiver.build(cause, message, buildSkip)
 }
 
 func (receiver *ErrProps) Wrapf(cause error, format string, args ...any) error {
-	return receiver.Build(cause, fmt.Sprintf(format, args...))
+	CheckNilCause(cause, "ErrProps.Wrapf")
+	return receiver.build(cause, fmt.Sprintf(format, args...), buildSkip)
+}
+
+// WrapIf wraps cause using the message set via WithMessage. It returns nil
+// when cause is nil, so it is safe in `return builder.WrapIf(err)`.
+func (receiver *ErrProps) WrapIf(cause error) error {
+	if cause == nil {
+		return nil
+	}
+	return receiver.build(cause, receiver.message, buildSkip)
+}
+
+var strictNilWrap atomic.Bool
+
+// SetStrictNilWrap enables or disables strict nil-wrap mode and returns the
+// previous setting.
+func SetStrictNilWrap(enabled bool) bool {
+	return strictNilWrap.Swap(enabled)
+}
+
+// CheckNilCause panics in strict nil-wrap mode when cause is nil.
+func CheckNilCause(cause error, op string) {
+	if cause == nil && strictNilWrap.Load() {
+		panic("flooerr: " + op + " called with a nil cause (strict nil-wrap mode)")
+	}
+}
+
+// StackMode selects how much of the stack a build records.
+type StackMode int32
+
+const (
+	// StackFull records up to 15 frames from the build site.
+	StackFull StackMode = iota
+	// StackReturn records only the build site, one frame per layer.
+	StackReturn
+	// StackNone records no frames.
+	StackNone
+)
+
+var stackMode atomic.Int32
+
+// SetStackMode sets the global stack mode and returns the previous one.
+func SetStackMode(mode StackMode) StackMode {
+	return StackMode(stackMode.Swap(int32(mode)))
+}
+
+// BuildArgs holds the properties of a build. It is passed by value so a
+// build does not allocate it.
+type BuildArgs struct {
+	// Message is the message set with WithMessage, and ErrMessage the text
+	// of the layer: Message, or the message passed to Build when unset.
+	Message    string
+	ErrMessage string
+	Code       Code
+	Cause      error
+	// StackTracePTR holds the captured program counters. It may be shared
+	// with other builds and must not be modified.
+	StackTracePTR []uintptr
+	Context       map[string]any
+	SDC           map[string]string
+	Op            string
+	PublicMessage string
+	InheritCode   bool
 }
 
 // BuildErrFunc is a function type for building errors from internal package
-type BuildErrFunc func(
-	message string,
-	errMessage string,
-	code Code,
-	cause error,
-	stackTracePTR []uintptr,
-	context map[string]any,
-	sdc map[string]string,
-) error
+type BuildErrFunc func(args BuildArgs) error
 
 var buildErrFunc BuildErrFunc
 
@@ -138,9 +278,61 @@ func (e *simpleError) Error() string {
 }
 
 func callers(skip int) []uintptr {
-	const depth = 15
-	var pcs [depth]uintptr
-	n := runtime.Callers(skip, pcs[:])
-	var st = pcs[0 : n-2]
-	return st
+	var key stackKey
+	n := runtime.Callers(skip, key.pcs[:])
+	if n <= 2 {
+		return nil
+	}
+	key.n = n - 2
+	return intern(key)
+}
+
+// caller records the single frame of the build site.
+func caller(skip int) []uintptr {
+	var key stackKey
+	key.n = runtime.Callers(skip, key.pcs[:1])
+	if key.n == 0 {
+		return nil
+	}
+	return intern(key)
+}
+
+const stackDepth = 15
+
+// stackKey identifies a captured stack by its program counters.
+type stackKey struct {
+	pcs [stackDepth]uintptr
+	n   int
+}
+
+// maxInternedStacks bounds the intern table; stacks captured once it is
+// full are allocated per error.
+const maxInternedStacks = 4096
+
+var (
+	internMu sync.RWMutex
+	interned = make(map[stackKey][]uintptr)
+)
+
+// intern returns a shared slice holding the stack of key, so errors built
+// at the same call site do not allocate their own copy. Callers must not
+// modify the returned slice.
+func intern(key stackKey) []uintptr {
+	internMu.RLock()
+	pcs, ok := interned[key]
+	internMu.RUnlock()
+	if ok {
+		return pcs
+	}
+
+	pcs = append([]uintptr(nil), key.pcs[:key.n]...)
+	internMu.Lock()
+	defer internMu.Unlock()
+	if existing, ok := interned[key]; ok {
+		return existing
+	}
+	if len(interned) < maxInternedStacks {
+		interned[key] = pcs
+	}
+	return pcs
 }
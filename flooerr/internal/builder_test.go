@@ -11,12 +11,8 @@ func TestCreate(t *testing.T) {
 		t.Fatal("Create() returned nil")
 	}
 
-	if props.context == nil {
-		t.Error("Expected non-nil context map")
-	}
-
-	if props.sdc == nil {
-		t.Error("Expected non-nil SDC map")
+	if props.context != nil || props.sdc != nil {
+		t.Error("Expected context and SDC maps to be allocated on first use")
 	}
 
 	if !props.withStackTrace {
@@ -58,6 +54,13 @@ func TestErrProps_WithCode_Chaining(t *testing.T) {
 	}
 }
 
+func TestErrProps_WithOp(t *testing.T) {
+	props := Create().WithOp("user.Service.Get")
+	if props.op != "user.Service.Get" {
+		t.Errorf("Expected op 'user.Service.Get', got '%s'", props.op)
+	}
+}
+
 func TestErrProps_WithStackTrace(t *testing.T) {
 	props := Create().WithStackTrace(false)
 	if props.withStackTrace {
@@ -281,15 +284,7 @@ func TestSetBuildErrFunc(t *testing.T) {
 	originalFunc := buildErrFunc
 
 	// Set a custom builder function
-	customBuilder := func(
-		message string,
-		errMessage string,
-		code Code,
-		cause error,
-		stackTracePTR []uintptr,
-		context map[string]any,
-		sdc map[string]string,
-	) error {
+	customBuilder := func(args BuildArgs) error {
 		return errors.New("custom error")
 	}
 
@@ -352,3 +347,143 @@ func TestCode_Type(t *testing.T) {
 		t.Errorf("Expected 'TEST_CODE', got '%s'", code)
 	}
 }
+
+func TestCode_Parent(t *testing.T) {
+	tests := map[Code]Code{
+		"PAYMENT.CARD.DECLINED": "PAYMENT.CARD",
+		"PAYMENT.CARD":          "PAYMENT",
+		"PAYMENT":               "",
+		"":                      "",
+	}
+	for code, expected := range tests {
+		if parent := code.Parent(); parent != expected {
+			t.Errorf("%q.Parent() = %q, expected %q", code, parent, expected)
+		}
+	}
+}
+
+func TestCode_Under(t *testing.T) {
+	tests := []struct {
+		code, prefix Code
+		expected     bool
+	}{
+		{"PAYMENT.CARD.DECLINED", "PAYMENT.CARD", true},
+		{"PAYMENT.CARD.DECLINED", "PAYMENT", true},
+		{"PAYMENT.CARD", "PAYMENT.CARD", true},
+		{"PAYMENT.CARDS", "PAYMENT.CARD", false},
+		{"PAYMENT", "PAYMENT.CARD", false},
+		{"PAYMENT", "", true},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if actual := tt.code.Under(tt.prefix); actual != tt.expected {
+			t.Errorf("%q.Under(%q) = %v, expected %v", tt.code, tt.prefix, actual, tt.expected)
+		}
+	}
+}
+
+func TestErrProps_WrapIf(t *testing.T) {
+	if err := Create().WithMessage("wrapped").WrapIf(nil); err != nil {
+		t.Errorf("Expected nil for nil cause, got %v", err)
+	}
+
+	originalErr := errors.New("original")
+	err := Create().WithMessage("wrapped").WrapIf(originalErr)
+	if err == nil {
+		t.Fatal("Expected non-nil error")
+	}
+}
+
+func TestSetStrictNilWrap(t *testing.T) {
+	if previous := SetStrictNilWrap(true); previous {
+		t.Error("Expected strict mode to be disabled by default")
+	}
+	defer SetStrictNilWrap(false)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Wrap with nil cause to panic in strict mode")
+		}
+	}()
+	_ = Create().Wrap(nil, "message")
+}
+
+func TestCheckNilCause_NonNil(t *testing.T) {
+	defer SetStrictNilWrap(SetStrictNilWrap(true))
+
+	CheckNilCause(errors.New("cause"), "Wrap")
+}
+
+func TestSetStackMode(t *testing.T) {
+	if previous := SetStackMode(StackReturn); previous != StackFull {
+		t.Errorf("Expected StackFull by default, got %d", previous)
+	}
+	defer SetStackMode(StackFull)
+
+	originalFunc := buildErrFunc
+	defer SetBuildErrFunc(originalFunc)
+
+	var captured []uintptr
+	SetBuildErrFunc(func(args BuildArgs) error {
+		captured = args.StackTracePTR
+		return errors.New(args.ErrMessage)
+	})
+
+	_ = Create().Build(nil, "test")
+	if len(captured) != 1 {
+		t.Errorf("Expected 1 frame in return mode, got %d", len(captured))
+	}
+
+	SetStackMode(StackNone)
+	_ = Create().Build(nil, "test")
+	if len(captured) != 0 {
+		t.Errorf("Expected no frames in none mode, got %d", len(captured))
+	}
+}
+
+func TestCallers_Interned(t *testing.T) {
+	var stacks [][]uintptr
+	for i := 0; i < 2; i++ {
+		stacks = append(stacks, callers(1))
+	}
+
+	if len(stacks[0]) == 0 || &stacks[0][0] != &stacks[1][0] {
+		t.Error("Expected stacks from the same call site to share storage")
+	}
+
+	if other := callers(1); &other[0] == &stacks[0][0] {
+		t.Error("Expected a different call site to get its own stack")
+	}
+}
+
+func TestErrProps_LazyMaps(t *testing.T) {
+	props := Create().WithSDC("trace_id", "trace_123")
+
+	if props.context != nil {
+		t.Error("Expected no context map until a context value is added")
+	}
+
+	if props.sdc["trace_id"] != "trace_123" {
+		t.Errorf("Expected SDC['trace_id'] = 'trace_123', got '%s'", props.sdc["trace_id"])
+	}
+}
+
+func TestErrProps_WithInheritCode(t *testing.T) {
+	originalFunc := buildErrFunc
+	defer SetBuildErrFunc(originalFunc)
+
+	var captured bool
+	SetBuildErrFunc(func(args BuildArgs) error {
+		captured = args.InheritCode
+		return errors.New(args.ErrMessage)
+	})
+
+	_ = Create().WithInheritCode(true).Build(nil, "test")
+	if !captured {
+		t.Error("Expected inheritCode to be passed to the build function")
+	}
+	_ = Create().Build(nil, "test")
+	if captured {
+		t.Error("Expected no inheritance by default")
+	}
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// InModule reports whether a frame belongs to the application: its function
+// starts with modulePrefix or, without a prefix, its file is outside the
+// standard library, the module cache and vendor directories.
+func InModule(function, file, modulePrefix string) bool {
+	if modulePrefix != "" {
+		return strings.HasPrefix(function, modulePrefix)
+	}
+	goroot := FrameGOROOT()
+	if goroot == "" {
+		return trimmedInModule(file)
+	}
+	if strings.HasPrefix(file, goroot+"/") {
+		return false
+	}
+	return !strings.Contains(file, "/pkg/mod/") && !strings.Contains(file, "/vendor/")
+}
+
+// trimmedInModule is InModule for binaries built with -trimpath, whose
+// files are named by import path: standard library files by their package,
+// module files by their module path, which is followed by @version outside
+// the main module.
+func trimmedInModule(file string) bool {
+	if strings.Contains(file, "@") {
+		return false
+	}
+	if main := mainModule(); main != "" && strings.HasPrefix(file, main+"/") {
+		return true
+	}
+	first, _, _ := strings.Cut(file, "/")
+	return strings.Contains(first, ".")
+}
+
+// FrameGOROOT returns the GOROOT recorded in the frames of this binary. It
+// is taken from the file of a runtime function rather than from the
+// deprecated runtime.GOROOT, so it stays right for binaries moved after the
+// build, and is "" for binaries built with -trimpath.
+var FrameGOROOT = sync.OnceValue(func() string {
+	fn := runtime.FuncForPC(reflect.ValueOf(runtime.Gosched).Pointer())
+	if fn == nil {
+		return ""
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	goroot, ok := strings.CutSuffix(file, "/src/runtime/proc.go")
+	if !ok {
+		return ""
+	}
+	return goroot
+})
+
+// mainModule returns the path of the main module, if recorded.
+var mainModule = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+})
+
+// SourceLine is one line of a source snippet.
+type SourceLine struct {
+	Number  int
+	Text    string
+	Current bool
+}
+
+// SourceSnippet returns up to context lines before and after line of file,
+// reading files through cache. It returns nil when the file is unreadable.
+func SourceSnippet(cache map[string][]string, file string, line, context int) []SourceLine {
+	lines, ok := cache[file]
+	if !ok {
+		lines = readLines(file)
+		cache[file] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+
+	first := max(1, line-context)
+	last := min(len(lines), line+context)
+	snippet := make([]SourceLine, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		snippet = append(snippet, SourceLine{
+			Number:  n,
+			Text:    strings.ReplaceAll(lines[n-1], "\t", "    "),
+			Current: n == line,
+		})
+	}
+	return snippet
+}
+
+// readLines returns the lines of path, or nil when it cannot be read.
+func readLines(path string) []string {
+	file, openErr := os.Open(path)
+	if openErr != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
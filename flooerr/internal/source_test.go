@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFrameGOROOT(t *testing.T) {
+	pcs := make([]uintptr, 1)
+	runtime.Callers(0, pcs)
+	frame, _ := runtime.CallersFrames(pcs).Next()
+
+	if goroot := FrameGOROOT(); goroot != "" && !strings.HasPrefix(frame.File, goroot+"/src/runtime/") {
+		t.Errorf("Expected %s to be under %s", frame.File, goroot)
+	}
+	if InModule(frame.Function, frame.File, "") {
+		t.Errorf("Expected %s not to be in the module", frame.Function)
+	}
+}
+
+func TestTrimmedInModule(t *testing.T) {
+	tests := map[string]bool{
+		"runtime/proc.go":                       false,
+		"net/http/server.go":                    false,
+		"github.com/lib/pq@v1.10.9/conn.go":     false,
+		"example.com/service/handler.go":        true,
+		mainModule() + "/flooerr/internal/x.go": mainModule() != "",
+	}
+
+	for file, expected := range tests {
+		if actual := trimmedInModule(file); actual != expected {
+			t.Errorf("%s: expected %v, got %v", file, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package flooerr
+
+import (
+	"iter"
+	"reflect"
+)
+
+// All iterates over err and every error reachable from it, depth-first and
+// outermost first. It follows both Unwrap() error and Unwrap() []error, so
+// errors.Join and fmt.Errorf with several %w verbs are walked branch by
+// branch. Errors already visited are skipped, which protects against
+// cyclic chains.
+func All(err error) iter.Seq[error] {
+	return func(yield func(error) bool) {
+		if err == nil {
+			return
+		}
+		var seen []error
+		walk(err, &seen, yield)
+	}
+}
+
+// walk visits err and its causes; it returns false once yield asks to stop.
+func walk(err error, seen *[]error, yield func(error) bool) bool {
+	for err != nil {
+		if visited(*seen, err) {
+			return true
+		}
+		*seen = append(*seen, err)
+
+		if !yield(err) {
+			return false
+		}
+
+		switch unwrapper := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, branch := range unwrapper.Unwrap() {
+				if branch != nil && !walk(branch, seen, yield) {
+					return false
+				}
+			}
+			return true
+		case interface{ Unwrap() error }:
+			err = unwrapper.Unwrap()
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+// visited reports whether err was already yielded. Errors of uncomparable
+// types cannot be compared and are never treated as repeated.
+func visited(seen []error, err error) bool {
+	if !reflect.TypeOf(err).Comparable() {
+		return false
+	}
+	for _, other := range seen {
+		if reflect.TypeOf(other).Comparable() && other == err {
+			return true
+		}
+	}
+	return false
+}
+
+// FlooErrs iterates over the FlooErr layers reachable from err, in the order
+// of All.
+func FlooErrs(err error) iter.Seq[FlooErr] {
+	return func(yield func(FlooErr) bool) {
+		for layer := range All(err) {
+			if flooErr, ok := asLayer(layer); ok {
+				if !yield(flooErr) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Frames iterates over the stack frames of every FlooErr layer, outermost
+// layer first. Layers built without a stack trace contribute no frames.
+func Frames(err error) iter.Seq[stacktrace] {
+	return func(yield func(stacktrace) bool) {
+		for flooErr := range FlooErrs(err) {
+			for _, frame := range flooErr.StackTrace() {
+				if !yield(frame) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ContextAll iterates over the context entries of every FlooErr layer,
+// outermost layer first and in key order within a layer. A key set on
+// several layers is yielded once per layer; use MergedContext for a single
+// value per key.
+func ContextAll(err error) iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		for flooErr := range FlooErrs(err) {
+			context := flooErr.Context()
+			for _, key := range sortedKeys(context) {
+				if !yield(key, context[key]) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package flooerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	base := errors.New("base")
+	left := Message("left").WithCode("LEFT").Error(base, "left")
+	right := errors.New("right")
+	joined := errors.Join(left, right)
+	top := Message("top").WithCode("TOP").Error(joined, "top")
+
+	var got []string
+	for e := range All(top) {
+		got = append(got, e.Error())
+	}
+
+	expected := []string{top.Error(), joined.Error(), left.Error(), "base", "right"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestAll_Nil(t *testing.T) {
+	for range All(nil) {
+		t.Error("Expected no errors for nil")
+	}
+}
+
+func TestAll_EarlyTermination(t *testing.T) {
+	err := Wrap(Wrap(errors.New("base"), "middle"), "top")
+
+	count := 0
+	for range All(err) {
+		count++
+		break
+	}
+
+	if count != 1 {
+		t.Errorf("Expected 1 iteration, got %d", count)
+	}
+}
+
+type cyclicError struct {
+	next error
+}
+
+func (e *cyclicError) Error() string { return "cyclic" }
+
+func (e *cyclicError) Unwrap() error { return e.next }
+
+func TestAll_Cycle(t *testing.T) {
+	first := &cyclicError{}
+	second := &cyclicError{next: first}
+	first.next = second
+
+	count := 0
+	for range All(first) {
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2 errors, got %d", count)
+	}
+}
+
+type sliceError []string
+
+func (e sliceError) Error() string { return "slice" }
+
+func TestAll_UncomparableError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", sliceError{"a"})
+
+	count := 0
+	for range All(err) {
+		count++
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2 errors, got %d", count)
+	}
+}
+
+func TestFlooErrs(t *testing.T) {
+	inner := Message("inner").WithCode("INNER").Error(errors.New("base"), "inner")
+	top := Message("top").WithCode("TOP").Error(fmt.Errorf("middle: %w", inner), "top")
+
+	var codes []string
+	for flooErr := range FlooErrs(top) {
+		codes = append(codes, flooErr.Code().String())
+	}
+
+	if fmt.Sprint(codes) != "[TOP INNER]" {
+		t.Errorf("Expected [TOP INNER], got %v", codes)
+	}
+
+	for range FlooErrs(top) {
+		break
+	}
+}
+
+func TestFrames(t *testing.T) {
+	inner := Message("inner").Error(nil, "inner")
+	top := Message("top").WithStackTrace(false).Error(inner, "top")
+
+	count := 0
+	for frame := range Frames(top) {
+		if frame.Function == "" {
+			t.Error("Expected frame function")
+		}
+		count++
+	}
+
+	if count != len(GetStackTrace(inner)) || count == 0 {
+		t.Errorf("Expected %d frames, got %d", len(GetStackTrace(inner)), count)
+	}
+
+	count = 0
+	for range Frames(top) {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 iteration, got %d", count)
+	}
+}
+
+func TestContextAll(t *testing.T) {
+	inner := Message("inner").WithContext("id", 1).WithContext("table", "users").Error(nil, "inner")
+	top := Message("top").WithContext("id", 2).Error(inner, "top")
+
+	var got []string
+	for key, value := range ContextAll(top) {
+		got = append(got, fmt.Sprintf("%s=%v", key, value))
+	}
+
+	if fmt.Sprint(got) != "[id=2 id=1 table=users]" {
+		t.Errorf("Unexpected context entries %v", got)
+	}
+
+	count := 0
+	for range ContextAll(top) {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 iteration, got %d", count)
+	}
+}
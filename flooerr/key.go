@@ -0,0 +1,132 @@
+package flooerr
+
+import (
+	"core-common-go/flooerr/internal"
+	"encoding/json"
+	"reflect"
+)
+
+// Key is a typed context key. Declare keys once and use them with With and
+// Lookup:
+//
+//	var UserIDKey = flooerr.NewKey[int64]("user_id")
+//
+//	props := flooerr.Message("not found")
+//	err := flooerr.With(props, UserIDKey, 42).Build(nil, "")
+//	id, ok := flooerr.Lookup(err, UserIDKey)
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a typed context key stored under name.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// KeyName returns the context map key.
+func (k Key[T]) KeyName() string {
+	return k.name
+}
+
+func (k Key[T]) String() string {
+	return k.name
+}
+
+// With adds value to the context of props under key and returns props.
+// Unlike WithContext, the value must have the type of the key.
+func With[T any](props *internal.ErrProps, key Key[T], value T) *internal.ErrProps {
+	return props.WithContext(key.name, value)
+}
+
+// Lookup searches the whole error chain, nearest layer first, for a context
+// value stored under key that converts to T.
+func Lookup[T any](err error, key Key[T]) (T, bool) {
+	return GetContextAs[T](err, key.name)
+}
+
+// GetContextAs is Lookup for an untyped key: it searches the whole error
+// chain, nearest layer first, for a context value stored under key that
+// converts to T. Numeric values are converted between numeric types when no
+// precision is lost, which covers values that went through JSON decoding
+// (float64 or json.Number). Returns false if no layer holds a value that
+// converts.
+func GetContextAs[T any](err error, key string) (T, bool) {
+	for _, layer := range UnwrapChain(err) {
+		flooErr, ok := asLayer(layer)
+		if !ok {
+			continue
+		}
+		value, exists := flooErr.Context()[key]
+		if !exists {
+			continue
+		}
+		if converted, ok := convertValue[T](value); ok {
+			return converted, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// convertValue converts value to T, allowing lossless numeric conversions.
+func convertValue[T any](value any) (T, bool) {
+	var zero T
+	if v, ok := value.(T); ok {
+		return v, true
+	}
+	if value == nil {
+		return zero, false
+	}
+
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			value = i
+		} else if f, err := number.Float64(); err == nil {
+			value = f
+		} else {
+			return zero, false
+		}
+	}
+
+	source := reflect.ValueOf(value)
+	if !isNumericKind(source.Kind()) || !isNumericKind(target.Kind()) {
+		if source.Type().ConvertibleTo(target) && source.Kind() == target.Kind() {
+			return source.Convert(target).Interface().(T), true
+		}
+		return zero, false
+	}
+
+	converted := source.Convert(target)
+	if !sameNumber(source, converted) {
+		return zero, false
+	}
+	return converted.Interface().(T), true
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// sameNumber reports whether converting a to b was lossless: the value
+// survives the round trip and keeps its sign.
+func sameNumber(a, b reflect.Value) bool {
+	back := b.Convert(a.Type())
+	return back.Interface() == a.Interface() && isNegative(a) == isNegative(b)
+}
+
+func isNegative(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float() < 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() < 0
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package flooerr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type userID int64
+
+func TestWith(t *testing.T) {
+	key := NewKey[int64]("user_id")
+	err := With(Message("test"), key, 42).Error(nil, "test")
+
+	value, ok := GetContextValue(err, "user_id").(int64)
+	if !ok || value != 42 {
+		t.Errorf("Expected int64(42) in context, got %#v", GetContextValue(err, "user_id"))
+	}
+
+	if key.String() != "user_id" || key.KeyName() != "user_id" {
+		t.Errorf("Unexpected key name '%s'", key.KeyName())
+	}
+}
+
+func TestLookup_NearestFirst(t *testing.T) {
+	key := NewKey[string]("request_id")
+	inner := With(Message("inner"), key, "inner-id").Error(nil, "inner")
+	middle := Message("middle").Error(inner, "middle")
+	outer := With(Message("outer"), key, "outer-id").Error(middle, "outer")
+
+	value, ok := Lookup(outer, key)
+	if !ok || value != "outer-id" {
+		t.Errorf("Expected 'outer-id', got '%s' (%v)", value, ok)
+	}
+
+	value, ok = Lookup(middle, key)
+	if !ok || value != "inner-id" {
+		t.Errorf("Expected 'inner-id' from deeper layer, got '%s' (%v)", value, ok)
+	}
+}
+
+func TestLookup_SkipsMismatchedLayer(t *testing.T) {
+	key := NewKey[int]("attempt")
+	inner := Message("inner").WithContext("attempt", 3).Error(nil, "inner")
+	outer := Message("outer").WithContext("attempt", "third").Error(inner, "outer")
+
+	value, ok := Lookup(outer, key)
+	if !ok || value != 3 {
+		t.Errorf("Expected 3 from inner layer, got %d (%v)", value, ok)
+	}
+}
+
+func TestLookup_NotFound(t *testing.T) {
+	key := NewKey[string]("missing")
+
+	if _, ok := Lookup(Message("test").Error(nil, "test"), key); ok {
+		t.Error("Expected Lookup to fail for missing key")
+	}
+
+	if _, ok := Lookup(errors.New("standard"), key); ok {
+		t.Error("Expected Lookup to fail for non-FlooErr")
+	}
+
+	if _, ok := Lookup(nil, key); ok {
+		t.Error("Expected Lookup to fail for nil")
+	}
+}
+
+func TestGetContextAs_JSONNumbers(t *testing.T) {
+	err := Message("test").
+		WithContext("float", float64(42)).
+		WithContext("fraction", 1.5).
+		WithContext("number", json.Number("7")).
+		WithContext("negative", -1).
+		WithContext("name", "alice").
+		Error(nil, "test")
+
+	if v, ok := GetContextAs[int64](err, "float"); !ok || v != 42 {
+		t.Errorf("Expected 42, got %d (%v)", v, ok)
+	}
+
+	if _, ok := GetContextAs[int](err, "fraction"); ok {
+		t.Error("Expected lossy float conversion to fail")
+	}
+
+	if v, ok := GetContextAs[userID](err, "number"); !ok || v != 7 {
+		t.Errorf("Expected 7, got %d (%v)", v, ok)
+	}
+
+	if _, ok := GetContextAs[uint](err, "negative"); ok {
+		t.Error("Expected negative to unsigned conversion to fail")
+	}
+
+	if v, ok := GetContextAs[string](err, "name"); !ok || v != "alice" {
+		t.Errorf("Expected 'alice', got '%s' (%v)", v, ok)
+	}
+
+	if _, ok := GetContextAs[string](err, "float"); ok {
+		t.Error("Expected number to string conversion to fail")
+	}
+
+	if _, ok := GetContextAs[int](err, "missing"); ok {
+		t.Error("Expected missing key to fail")
+	}
+}
+
+func TestGetContextAs_WholeChain(t *testing.T) {
+	inner := Message("inner").WithContext("user_id", 7).Error(nil, "inner")
+	outer := Message("outer").WithContext("user_id", "seven").Error(inner, "outer")
+
+	if v, ok := GetContextAs[int64](outer, "user_id"); !ok || v != 7 {
+		t.Errorf("Expected 7 from the inner layer, got %d (%v)", v, ok)
+	}
+	if v, ok := GetContextAs[string](outer, "user_id"); !ok || v != "seven" {
+		t.Errorf("Expected the outer value first, got '%s' (%v)", v, ok)
+	}
+	if v, ok := GetContextAs[int](Wrap(inner, "wrapped"), "user_id"); !ok || v != 7 {
+		t.Errorf("Expected the value under a plain wrap, got %d (%v)", v, ok)
+	}
+}
+
+func TestConvertValue_Overflow(t *testing.T) {
+	if _, ok := convertValue[int8](300); ok {
+		t.Error("Expected overflowing conversion to fail")
+	}
+
+	if _, ok := convertValue[int64](float64(1 << 62)); !ok {
+		t.Error("Expected exact large float to convert")
+	}
+
+	if _, ok := convertValue[int](nil); ok {
+		t.Error("Expected nil to fail")
+	}
+}
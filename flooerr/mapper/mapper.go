@@ -0,0 +1,220 @@
+// Package mapper translates foreign errors into FlooErrs at a service
+// boundary:
+//
+//	var errs = mapper.Default.With( // service rules first, then the defaults
+//		mapper.MatchIs(sql.ErrNoRows).WithCode("USER_NOT_FOUND"),
+//	)
+//
+//	return errs.Map(err)
+//
+// It is a package of its own so that flooerr does not depend on the
+// packages whose errors Default maps.
+package mapper
+
+import (
+	"context"
+	"core-common-go/flooerr"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Rule matches errors for a Mapper and describes the layer it wraps them
+// with. Rules are values; the With methods return modified copies:
+//
+//	mapper.MatchIs(sql.ErrNoRows).WithCode("USER_NOT_FOUND").WithMessage("user not found")
+type Rule struct {
+	match       func(error) bool
+	code        flooerr.ErrCode
+	message     string
+	context     map[string]any
+	contextFrom func(error) map[string]any
+}
+
+// MatchIs matches errors for which errors.Is(err, target) holds.
+func MatchIs(target error) Rule {
+	return Rule{match: func(err error) bool { return errors.Is(err, target) }}
+}
+
+// MatchAs matches errors for which errors.As finds a T in the chain.
+func MatchAs[T error]() Rule {
+	return Rule{match: func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}}
+}
+
+// MatchFunc matches errors for which match returns true.
+func MatchFunc(match func(error) bool) Rule {
+	return Rule{match: match}
+}
+
+// MatchMessage matches errors whose Error() matches the regular expression
+// pattern. It panics if pattern does not compile, like regexp.MustCompile.
+func MatchMessage(pattern string) Rule {
+	re := regexp.MustCompile(pattern)
+	return Rule{match: func(err error) bool { return re.MatchString(err.Error()) }}
+}
+
+// WithCode sets the code of the wrapping layer.
+func (r Rule) WithCode(code flooerr.ErrCode) Rule {
+	r.code = code
+	return r
+}
+
+// WithMessage sets the message of the wrapping layer. Without one, the
+// mapped error keeps the text of the original.
+func (r Rule) WithMessage(message string) Rule {
+	r.message = message
+	return r
+}
+
+// WithContext adds a context value to the wrapping layer.
+func (r Rule) WithContext(key string, value any) Rule {
+	r.context = maps.Clone(r.context)
+	if r.context == nil {
+		r.context = make(map[string]any)
+	}
+	r.context[key] = value
+	return r
+}
+
+// WithContextFrom adds the context returned by fn for the matched error to
+// the wrapping layer, after the values set with WithContext.
+func (r Rule) WithContextFrom(fn func(err error) map[string]any) Rule {
+	r.contextFrom = fn
+	return r
+}
+
+// Mapper translates foreign errors into FlooErrs at a service boundary. The
+// first matching rule wraps the error in a layer with the rule's code,
+// message and context, keeping the original as cause. Mappers are
+// immutable and safe for concurrent use.
+type Mapper struct {
+	rules  []Rule
+	parent *Mapper
+}
+
+// New returns a Mapper with rules, tried in order.
+func New(rules ...Rule) *Mapper {
+	return &Mapper{rules: rules}
+}
+
+// With returns a Mapper that tries rules before the rules of m, so a
+// service can layer its own rules over library defaults:
+//
+//	var errs = mapper.Default.With(
+//		mapper.MatchIs(sql.ErrNoRows).WithCode("USER_NOT_FOUND"),
+//	)
+func (m *Mapper) With(rules ...Rule) *Mapper {
+	return &Mapper{rules: rules, parent: m}
+}
+
+// Map wraps err according to the first matching rule. It returns err
+// unchanged when it is nil, already carries a code, or matches no rule.
+func (m *Mapper) Map(err error) error {
+	if err == nil {
+		return nil
+	}
+	for flooErr := range flooerr.FlooErrs(err) {
+		if flooErr.Code() != "" {
+			return err
+		}
+	}
+	rule, ok := m.find(err)
+	if !ok {
+		return err
+	}
+
+	props := flooerr.Code(rule.code).WithMessage(rule.message)
+	for key, value := range rule.context {
+		props.WithContext(key, value)
+	}
+	if rule.contextFrom != nil {
+		for key, value := range rule.contextFrom(err) {
+			props.WithContext(key, value)
+		}
+	}
+	return props.BuildSkip(0, err, rule.message)
+}
+
+// find returns the first rule matching err, looking at the rules of m
+// before those of its parents.
+func (m *Mapper) find(err error) (Rule, bool) {
+	for mapper := m; mapper != nil; mapper = mapper.parent {
+		for _, rule := range mapper.rules {
+			if rule.match != nil && rule.match(err) {
+				return rule, true
+			}
+		}
+	}
+	return Rule{}, false
+}
+
+// Default maps common standard library errors to the codes flooerr
+// declares for them: canceled and expired contexts, fs.ErrNotExist,
+// fs.ErrExist, fs.ErrPermission, sql.ErrNoRows, io.EOF and
+// io.ErrUnexpectedEOF, JSON syntax and type errors, *strconv.NumError and
+// *net.OpError. Path, offset and address details are added to the context.
+var Default = New(
+	MatchIs(context.Canceled).WithCode(flooerr.CodeCanceled),
+	MatchIs(context.DeadlineExceeded).WithCode(flooerr.CodeDeadlineExceeded),
+	MatchIs(os.ErrDeadlineExceeded).WithCode(flooerr.CodeDeadlineExceeded),
+	MatchIs(fs.ErrNotExist).WithCode(flooerr.CodeNotFound).WithContextFrom(pathContext),
+	MatchIs(fs.ErrExist).WithCode(flooerr.CodeAlreadyExists).WithContextFrom(pathContext),
+	MatchIs(fs.ErrPermission).WithCode(flooerr.CodePermissionDenied).WithContextFrom(pathContext),
+	MatchIs(sql.ErrNoRows).WithCode(flooerr.CodeNotFound),
+	MatchIs(io.EOF).WithCode(flooerr.CodeUnexpectedEOF),
+	MatchIs(io.ErrUnexpectedEOF).WithCode(flooerr.CodeUnexpectedEOF),
+	MatchAs[*json.SyntaxError]().WithCode(flooerr.CodeInvalidJSON).WithContextFrom(jsonContext),
+	MatchAs[*json.UnmarshalTypeError]().WithCode(flooerr.CodeInvalidJSON).WithContextFrom(jsonContext),
+	MatchAs[*strconv.NumError]().WithCode(flooerr.CodeInvalidArgument).WithContextFrom(numContext),
+	MatchAs[*net.OpError]().WithCode(flooerr.CodeNetwork).WithContextFrom(netContext),
+)
+
+func pathContext(err error) map[string]any {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		return nil
+	}
+	return map[string]any{"path": pathErr.Path, "op": pathErr.Op}
+}
+
+func jsonContext(err error) map[string]any {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return map[string]any{"offset": syntaxErr.Offset}
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return map[string]any{"offset": typeErr.Offset, "field": typeErr.Field, "json_type": typeErr.Value}
+	}
+	return nil
+}
+
+func numContext(err error) map[string]any {
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		return nil
+	}
+	return map[string]any{"func": numErr.Func, "input": numErr.Num}
+}
+
+func netContext(err error) map[string]any {
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return nil
+	}
+	values := map[string]any{"op": opErr.Op, "net": opErr.Net}
+	if opErr.Addr != nil {
+		values["addr"] = opErr.Addr.String()
+	}
+	return values
+}
@@ -0,0 +1,160 @@
+package mapper
+
+import (
+	"context"
+	"core-common-go/flooerr"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+type quotaError struct{ limit int }
+
+func (e *quotaError) Error() string { return fmt.Sprintf("quota of %d exceeded", e.limit) }
+
+func TestMapper_Rules(t *testing.T) {
+	errQuota := &quotaError{limit: 10}
+	m := New(
+		MatchIs(sql.ErrNoRows).WithCode("USER_NOT_FOUND").WithMessage("user not found").WithContext("table", "users"),
+		MatchAs[*quotaError]().WithCode("QUOTA"),
+		MatchFunc(func(err error) bool { return err.Error() == "special" }).WithCode("SPECIAL"),
+		MatchMessage(`^redis: (nil|connection pool timeout)$`).WithCode("CACHE"),
+	)
+
+	tests := []struct {
+		err     error
+		code    flooerr.ErrCode
+		message string
+	}{
+		{fmt.Errorf("scan: %w", sql.ErrNoRows), "USER_NOT_FOUND", "user not found; caused by: scan: sql: no rows in result set"},
+		{fmt.Errorf("charge: %w", errQuota), "QUOTA", "charge: quota of 10 exceeded"},
+		{errors.New("special"), "SPECIAL", "special"},
+		{errors.New("redis: nil"), "CACHE", "redis: nil"},
+	}
+	for _, tt := range tests {
+		mapped := m.Map(tt.err)
+		if code := flooerr.GetCode(mapped); code != tt.code {
+			t.Errorf("%v: expected code %s, got %s", tt.err, tt.code, code)
+		}
+		if mapped.Error() != tt.message {
+			t.Errorf("%v: expected text %q, got %q", tt.err, tt.message, mapped.Error())
+		}
+		if !errors.Is(mapped, tt.err) {
+			t.Errorf("%v: expected the original as cause", tt.err)
+		}
+	}
+
+	mapped := m.Map(sql.ErrNoRows)
+	if flooerr.GetMessage(mapped) != "user not found" || flooerr.GetContextValue(mapped, "table") != "users" {
+		t.Errorf("Unexpected layer %+v", mapped)
+	}
+	if len(flooerr.GetStackTrace(mapped)) == 0 || flooerr.GetStackTrace(mapped)[0].Function != "core-common-go/flooerr/mapper.TestMapper_Rules" {
+		t.Errorf("Expected the stack to start at the caller of Map, got %v", flooerr.GetStackTrace(mapped))
+	}
+}
+
+func TestMapper_Unchanged(t *testing.T) {
+	m := New(MatchFunc(func(error) bool { return true }).WithCode("ANY"))
+
+	if m.Map(nil) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+
+	coded := flooerr.Code("ALREADY").Wrap(io.EOF, "read failed")
+	for _, err := range []error{coded, fmt.Errorf("handler: %w", coded)} {
+		if m.Map(err) != err {
+			t.Errorf("Expected %v to be returned unchanged", err)
+		}
+	}
+
+	if err := errors.New("other"); New().Map(err) != err {
+		t.Error("Expected unmatched errors to be returned unchanged")
+	}
+
+	uncoded := flooerr.Wrap(io.EOF, "read failed")
+	if flooerr.GetCode(m.Map(uncoded)) != "ANY" {
+		t.Error("Expected FlooErrs without a code to be mapped")
+	}
+}
+
+func TestMapper_With(t *testing.T) {
+	service := Default.With(
+		MatchIs(sql.ErrNoRows).WithCode("USER_NOT_FOUND"),
+	)
+
+	if code := flooerr.GetCode(service.Map(sql.ErrNoRows)); code != "USER_NOT_FOUND" {
+		t.Errorf("Expected the service rule to win, got %s", code)
+	}
+	if code := flooerr.GetCode(service.Map(context.Canceled)); code != flooerr.CodeCanceled {
+		t.Errorf("Expected the default rules to apply, got %s", code)
+	}
+	if code := flooerr.GetCode(Default.Map(sql.ErrNoRows)); code != flooerr.CodeNotFound {
+		t.Errorf("Expected the defaults to be unchanged, got %s", code)
+	}
+}
+
+func TestRule_WithContextCopies(t *testing.T) {
+	base := MatchIs(io.EOF).WithContext("a", 1)
+	extended := base.WithContext("b", 2)
+
+	if len(base.context) != 1 || len(extended.context) != 2 {
+		t.Errorf("Expected WithContext to copy the context, got %v and %v", base.context, extended.context)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	_, openErr := os.Open(filepath.Join(t.TempDir(), "missing.txt"))
+	_, numErr := strconv.Atoi("forty-two")
+	syntaxErr := json.Unmarshal([]byte(`{"a":`), &struct{}{})
+	typeErr := json.Unmarshal([]byte(`{"age":"old"}`), &struct{ Age int }{})
+	opErr := &net.OpError{Op: "dial", Net: "tcp", Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5432}, Err: errors.New("refused")}
+
+	tests := []struct {
+		err     error
+		code    flooerr.ErrCode
+		context map[string]any
+	}{
+		{context.Canceled, flooerr.CodeCanceled, nil},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), flooerr.CodeDeadlineExceeded, nil},
+		{os.ErrDeadlineExceeded, flooerr.CodeDeadlineExceeded, nil},
+		{openErr, flooerr.CodeNotFound, map[string]any{"op": "open"}},
+		{os.ErrExist, flooerr.CodeAlreadyExists, nil},
+		{os.ErrPermission, flooerr.CodePermissionDenied, nil},
+		{sql.ErrNoRows, flooerr.CodeNotFound, nil},
+		{io.EOF, flooerr.CodeUnexpectedEOF, nil},
+		{io.ErrUnexpectedEOF, flooerr.CodeUnexpectedEOF, nil},
+		{syntaxErr, flooerr.CodeInvalidJSON, nil},
+		{typeErr, flooerr.CodeInvalidJSON, map[string]any{"field": "age", "json_type": "string"}},
+		{numErr, flooerr.CodeInvalidArgument, map[string]any{"func": "Atoi", "input": "forty-two"}},
+		{opErr, flooerr.CodeNetwork, map[string]any{"op": "dial", "net": "tcp", "addr": "10.0.0.1:5432"}},
+	}
+
+	for _, tt := range tests {
+		mapped := Default.Map(tt.err)
+		if code := flooerr.GetCode(mapped); code != tt.code {
+			t.Errorf("%v: expected code %s, got %s", tt.err, tt.code, code)
+		}
+		if mapped.Error() != tt.err.Error() {
+			t.Errorf("%v: expected the original text, got %q", tt.err, mapped.Error())
+		}
+		for key, value := range tt.context {
+			if actual := flooerr.GetContextValue(mapped, key); actual != value {
+				t.Errorf("%v: expected context[%s] = %v, got %v", tt.err, key, value, actual)
+			}
+		}
+	}
+
+	if _, ok := flooerr.GetContextAs[int64](Default.Map(syntaxErr), "offset"); !ok {
+		t.Error("Expected the syntax error offset in the context")
+	}
+	if path, _ := flooerr.GetContextAs[string](Default.Map(openErr), "path"); filepath.Base(path) != "missing.txt" {
+		t.Errorf("Expected the path in the context, got %q", path)
+	}
+}
@@ -0,0 +1,103 @@
+package flooerr
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func opTestError() error {
+	err := Op("repo.Find").Wrap(sql.ErrNoRows, "find failed")
+	err = Op("user.Service.Get").Wrap(err, "get failed")
+	return Op("api.GetUser").WithCode("USER_ERR").Wrap(err, "request failed")
+}
+
+func TestOpPath(t *testing.T) {
+	expected := "api.GetUser: user.Service.Get: repo.Find: " + sql.ErrNoRows.Error()
+
+	if path := OpPath(opTestError()); path != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, path)
+	}
+}
+
+func TestOpPath_SkipsLayersWithoutOp(t *testing.T) {
+	inner := Op("repo.Find").Error(nil, "not found")
+	err := Wrap(fmt.Errorf("cache: %w", inner), "load failed")
+	err = Op("api.GetUser").Wrap(err, "request failed")
+
+	if path := OpPath(err); path != "api.GetUser: repo.Find: not found" {
+		t.Errorf("Unexpected path '%s'", path)
+	}
+}
+
+func TestOpPath_NoOp(t *testing.T) {
+	if path := OpPath(Wrap(errors.New("base"), "top")); path != "" {
+		t.Errorf("Expected empty path, got '%s'", path)
+	}
+
+	if path := OpPath(nil); path != "" {
+		t.Errorf("Expected empty path for nil, got '%s'", path)
+	}
+}
+
+func TestErr_Error_WithOp(t *testing.T) {
+	err := opTestError()
+	if err.Error() != OpPath(err) {
+		t.Errorf("Expected Error() to use the op path, got '%s'", err.Error())
+	}
+
+	if err := Op("repo.Find").Error(nil, "not found"); err.Error() != "repo.Find: not found" {
+		t.Errorf("Unexpected error '%s'", err.Error())
+	}
+
+	if err := Op("repo.Find").Error(nil, ""); err.Error() != "repo.Find" {
+		t.Errorf("Unexpected error '%s'", err.Error())
+	}
+
+	repeated := Op("repo.Find").Wrap(Op("repo.Find").Error(nil, "not found"), "retry")
+	if repeated.Error() != "repo.Find: not found" {
+		t.Errorf("Expected repeated op to collapse, got '%s'", repeated.Error())
+	}
+}
+
+func TestErr_Error_CollapsesRepeatedMessages(t *testing.T) {
+	err := Wrap(Wrap(Wrap(errors.New("base"), "failed"), "failed"), "failed")
+
+	if err.Error() != "failed; caused by: base" {
+		t.Errorf("Expected repeated messages to collapse, got '%s'", err.Error())
+	}
+
+	if err := Wrap(errors.New("base"), ""); err.Error() != "base" {
+		t.Errorf("Expected empty message to render as its cause, got '%s'", err.Error())
+	}
+}
+
+func TestParse_Op(t *testing.T) {
+	chain := ParseChain(opTestError())
+
+	if chain[0].Op != "api.GetUser" || chain[2].Op != "repo.Find" || chain[3].Op != "" {
+		t.Errorf("Unexpected ops %q %q %q", chain[0].Op, chain[2].Op, chain[3].Op)
+	}
+}
+
+func TestOp_Rendering(t *testing.T) {
+	err := opTestError()
+
+	if detail := fmt.Sprintf("%+v", err); !strings.Contains(detail, "    op: user.Service.Get\n") {
+		t.Errorf("Expected op in detail output:\n%s", detail)
+	}
+
+	data, _ := json.Marshal(err)
+	if !strings.Contains(string(data), `"op":"api.GetUser"`) {
+		t.Errorf("Expected op in JSON output: %s", data)
+	}
+
+	encoded, _ := MarshalBinary(err)
+	decoded, _ := UnmarshalBinary(encoded)
+	if OpPath(decoded) != OpPath(err) {
+		t.Errorf("Expected op path to survive binary encoding, got '%s'", OpPath(decoded))
+	}
+}
@@ -3,18 +3,30 @@ package flooerr
 import (
 	"core-common-go/flooerr/internal"
 	"errors"
+	"strings"
+	"time"
 )
 
 // ErrorInfo contains all information extracted from a FlooErr
 type ErrorInfo struct {
-	Code       internal.Code
-	Message    string
-	ErrorMsg   string
-	Context    map[string]any
-	SDC        map[string]string
-	StackTrace []stacktrace
-	Cause      error
-	IsFlooErr  bool
+	Code          internal.Code
+	Message       string
+	ErrorMsg      string
+	Context       map[string]any
+	SDC           map[string]string
+	StackTrace    []stacktrace
+	Cause         error
+	IsFlooErr     bool
+	Op            string
+	ID            string
+	Time          time.Time
+	PublicMessage string
+	// EffectiveCode is the outermost non-empty code from this layer down,
+	// while Code is the code of the layer itself.
+	EffectiveCode internal.Code
+	// Text is what the layer adds to the message of its cause: the text
+	// given to Build, or the whole ErrorMsg for foreign errors.
+	Text string
 }
 
 // Parse extracts all information from an error.
@@ -31,19 +43,30 @@ func Parse(err error) ErrorInfo {
 	if !ok {
 		return ErrorInfo{
 			ErrorMsg:  err.Error(),
+			Text:      err.Error(),
 			IsFlooErr: false,
 		}
 	}
 
+	return infoOf(flooErr)
+}
+
+func infoOf(flooErr FlooErr) ErrorInfo {
 	return ErrorInfo{
-		Code:       flooErr.Code(),
-		Message:    flooErr.Message(),
-		ErrorMsg:   flooErr.Error(),
-		Context:    flooErr.Context(),
-		SDC:        flooErr.SDC(),
-		StackTrace: flooErr.StackTrace(),
-		Cause:      flooErr.Unwrap(),
-		IsFlooErr:  true,
+		Code:          flooErr.Code(),
+		Message:       flooErr.Message(),
+		ErrorMsg:      flooErr.Error(),
+		Text:          layerText(flooErr),
+		Context:       flooErr.Context(),
+		SDC:           flooErr.SDC(),
+		StackTrace:    flooErr.StackTrace(),
+		Cause:         flooErr.Unwrap(),
+		IsFlooErr:     true,
+		Op:            opOf(flooErr),
+		ID:            idOf(flooErr).String(),
+		Time:          timeOf(flooErr),
+		PublicMessage: publicOf(flooErr),
+		EffectiveCode: EffectiveCode(flooErr),
 	}
 }
 
@@ -53,6 +76,9 @@ func AsFlooErr(err error) (FlooErr, bool) {
 	if err == nil {
 		return nil, false
 	}
+	if flooErr, ok := asLayer(err); ok {
+		return flooErr, true
+	}
 
 	var flooErr FlooErr
 	if errors.As(err, &flooErr) {
@@ -72,6 +98,18 @@ func GetCode(err error) internal.Code {
 	return flooErr.Code()
 }
 
+// EffectiveCode returns the outermost non-empty code in the chain of err.
+// Unlike GetCode, it looks past layers without a code, such as those added
+// by Wrap.
+func EffectiveCode(err error) internal.Code {
+	for flooErr := range FlooErrs(err) {
+		if code := flooErr.Code(); code != "" {
+			return code
+		}
+	}
+	return ""
+}
+
 // GetCodeString extracts the error code as a string from an error.
 // Returns empty string if the error is not a FlooErr.
 func GetCodeString(err error) string {
@@ -167,8 +205,7 @@ func UnwrapChain(err error) []error {
 	for current != nil {
 		chain = append(chain, current)
 
-		flooErr, ok := AsFlooErr(current)
-		if ok {
+		if flooErr, ok := asLayer(current); ok {
 			current = flooErr.Unwrap()
 		} else {
 			// Try standard errors.Unwrap for non-FlooErr errors
@@ -212,3 +249,48 @@ func HasSDCKey(err error, key string) bool {
 	_, exists := sdc[key]
 	return exists
 }
+
+// OpPath returns the operations recorded with WithOp along the chain,
+// outermost first, followed by the message of the root cause:
+//
+//	api.GetUser: user.Service.Get: repo.Find: sql: no rows
+//
+// It returns "" when no layer records an op.
+func OpPath(err error) string {
+	var path []string
+	var root error
+	for layer := range All(err) {
+		if op := opOf(layer); op != "" && (len(path) == 0 || path[len(path)-1] != op) {
+			path = append(path, op)
+		}
+		root = layer
+	}
+	if len(path) == 0 {
+		return ""
+	}
+
+	if flooErr, ok := asLayer(root); ok {
+		if message := layerText(flooErr); message != "" {
+			path = append(path, message)
+		}
+	} else {
+		path = append(path, root.Error())
+	}
+	return strings.Join(path, ": ")
+}
+
+// opOf returns the op of a single chain element, if it records one.
+func opOf(err error) string {
+	if layer, ok := err.(interface{ Op() string }); ok {
+		return layer.Op()
+	}
+	return ""
+}
+
+// asLayer reports whether a single chain element is itself a FlooErr,
+// without searching its causes like AsFlooErr does.
+func asLayer(err error) (FlooErr, bool) {
+	//flooerrlint:ignore assert
+	flooErr, ok := err.(FlooErr)
+	return flooErr, ok
+}
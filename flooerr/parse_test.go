@@ -300,3 +300,65 @@ func TestHasSDCKey(t *testing.T) {
 		t.Error("Expected HasSDCKey to return false for nonexistent key")
 	}
 }
+
+func TestEffectiveCode(t *testing.T) {
+	coded := Code("NOT_FOUND").Error(errors.New("sql: no rows"), "find failed")
+	wrapped := Wrap(Wrap(coded, "get user failed"), "handler failed")
+
+	if code := GetCode(wrapped); code != "" {
+		t.Errorf("Expected the outer layer to have no code, got %q", code)
+	}
+	if code := EffectiveCode(wrapped); code != "NOT_FOUND" {
+		t.Errorf("Expected NOT_FOUND, got %q", code)
+	}
+	if code := EffectiveCode(Code("USER_ERR").Wrap(wrapped, "outer")); code != "USER_ERR" {
+		t.Errorf("Expected the outermost code, got %q", code)
+	}
+	if code := EffectiveCode(errors.Join(errors.New("plain"), wrapped)); code != "NOT_FOUND" {
+		t.Errorf("Expected joined errors to be searched, got %q", code)
+	}
+	if EffectiveCode(errors.New("plain")) != "" || EffectiveCode(nil) != "" {
+		t.Error("Expected no code without FlooErrs")
+	}
+}
+
+func TestErrProps_WithInheritCode(t *testing.T) {
+	cause := Code("NOT_FOUND").Error(nil, "find failed")
+
+	inherited := Message("get user failed").WithInheritCode(true).Wrap(Wrap(cause, "lookup"), "")
+	if code := GetCode(inherited); code != "NOT_FOUND" {
+		t.Errorf("Expected the layer to inherit NOT_FOUND, got %q", code)
+	}
+
+	overridden := Code("USER_ERR").WithInheritCode(true).Wrap(cause, "get user failed")
+	if code := GetCode(overridden); code != "USER_ERR" {
+		t.Errorf("Expected WithCode to override, got %q", code)
+	}
+
+	if code := GetCode(Message("m").WithInheritCode(false).Wrap(cause, "")); code != "" {
+		t.Errorf("Expected no code without inheritance, got %q", code)
+	}
+	if code := GetCode(Message("m").WithInheritCode(true).Wrap(errors.New("plain"), "")); code != "" {
+		t.Errorf("Expected no code to inherit from a plain error, got %q", code)
+	}
+}
+
+func TestParseChain_EffectiveCode(t *testing.T) {
+	inner := Code("NOT_FOUND").Error(nil, "find failed")
+	chain := ParseChain(Wrap(Code("USER_ERR").Wrap(Wrap(inner, "query"), "get user"), "handler"))
+
+	expected := []struct{ code, effective ErrCode }{
+		{"", "USER_ERR"},
+		{"USER_ERR", "USER_ERR"},
+		{"", "NOT_FOUND"},
+		{"NOT_FOUND", "NOT_FOUND"},
+	}
+	if len(chain) != len(expected) {
+		t.Fatalf("Expected %d layers, got %d", len(expected), len(chain))
+	}
+	for i, info := range chain {
+		if info.Code != expected[i].code || info.EffectiveCode != expected[i].effective {
+			t.Errorf("Layer %d: expected %q/%q, got %q/%q", i, expected[i].code, expected[i].effective, info.Code, info.EffectiveCode)
+		}
+	}
+}
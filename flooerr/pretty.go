@@ -0,0 +1,189 @@
+package flooerr
+
+import (
+	"core-common-go/flooerr/internal"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ColorMode controls ANSI colors in Pretty.
+type ColorMode int
+
+const (
+	// ColorAuto colors the output when Output is a terminal and NO_COLOR is
+	// not set.
+	ColorAuto ColorMode = iota
+	// ColorAlways always colors the output.
+	ColorAlways
+	// ColorNever never colors the output.
+	ColorNever
+)
+
+// DefaultSourceLines is the number of source lines shown before and after
+// each in-module frame.
+const DefaultSourceLines = 2
+
+// PrettyOptions configures Pretty. The zero value auto-detects colors for
+// os.Stderr and shows DefaultSourceLines around in-module frames.
+type PrettyOptions struct {
+	Color ColorMode
+	// Output is the file the rendering is written to, used to detect a
+	// terminal in ColorAuto mode. It defaults to os.Stderr.
+	Output *os.File
+	// ModulePrefix marks frames whose function starts with it as in-module.
+	// By default every frame outside GOROOT, the module cache and vendor
+	// directories is in-module.
+	ModulePrefix string
+	// SourceLines is the number of lines shown around in-module frames; 0
+	// means DefaultSourceLines.
+	SourceLines int
+	// NoSource disables source snippets.
+	NoSource bool
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiCyan   = "\x1b[36m"
+	ansiYellow = "\x1b[33m"
+)
+
+// Pretty renders the chain of err for humans, in the spirit of a good test
+// failure: each layer with its code and message, context and SDC as aligned
+// tables, and stack frames with in-module frames highlighted, dependency
+// and runtime frames dimmed, and source lines around in-module frames.
+func Pretty(err error, opts PrettyOptions) string {
+	if err == nil {
+		return ""
+	}
+	p := prettyPrinter{opts: opts, color: opts.useColor(), sources: make(map[string][]string)}
+	if p.opts.SourceLines <= 0 {
+		p.opts.SourceLines = DefaultSourceLines
+	}
+
+	for i, layer := range UnwrapChain(err) {
+		if i > 0 {
+			p.b.WriteString(p.paint(ansiDim, "caused by: "))
+		}
+		flooErr, ok := asLayer(layer)
+		if !ok {
+			p.b.WriteString(p.paint(ansiBold, layer.Error()) + "\n")
+			continue
+		}
+		p.layer(flooErr)
+	}
+	return p.b.String()
+}
+
+func (o PrettyOptions) useColor() bool {
+	switch o.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if _, set := os.LookupEnv("NO_COLOR"); set {
+		return false
+	}
+	output := o.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	info, statErr := output.Stat()
+	return statErr == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type prettyPrinter struct {
+	b       strings.Builder
+	opts    PrettyOptions
+	color   bool
+	sources map[string][]string
+}
+
+func (p *prettyPrinter) paint(style, text string) string {
+	if !p.color {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+func (p *prettyPrinter) layer(flooErr FlooErr) {
+	p.b.WriteString(p.paint(ansiBold, layerText(flooErr)))
+	if code := flooErr.Code(); code != "" {
+		p.b.WriteString(" " + p.paint(ansiBold+ansiRed, "["+code.String()+"]"))
+	}
+	p.b.WriteString("\n")
+
+	if op := opOf(flooErr); op != "" {
+		fmt.Fprintf(&p.b, "    %s %s\n", p.paint(ansiCyan, "op:"), op)
+	}
+	if message := flooErr.Message(); message != "" && message != layerText(flooErr) {
+		fmt.Fprintf(&p.b, "    %s %s\n", p.paint(ansiCyan, "message:"), message)
+	}
+
+	context := flooErr.Context()
+	values := make(map[string]string, len(context))
+	for key, value := range context {
+		values[key] = fmt.Sprintf("%v", value)
+	}
+	p.table("context", values)
+	p.table("sdc", flooErr.SDC())
+
+	if stack := flooErr.StackTrace(); len(stack) > 0 {
+		fmt.Fprintf(&p.b, "    %s\n", p.paint(ansiCyan, "stack:"))
+		for _, frame := range stack {
+			p.frame(frame)
+		}
+	}
+}
+
+// table writes values as a two-column table with aligned values.
+func (p *prettyPrinter) table(title string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	keys := sortedKeys(values)
+	width := 0
+	for _, key := range keys {
+		width = max(width, len(key))
+	}
+
+	fmt.Fprintf(&p.b, "    %s\n", p.paint(ansiCyan, title+":"))
+	for _, key := range keys {
+		padding := strings.Repeat(" ", width-len(key))
+		fmt.Fprintf(&p.b, "        %s%s  %s\n", p.paint(ansiCyan, key), padding, values[key])
+	}
+}
+
+func (p *prettyPrinter) frame(frame stacktrace) {
+	location := fmt.Sprintf("%s:%d", frame.File, frame.Line)
+	if !internal.InModule(frame.Function, frame.File, p.opts.ModulePrefix) {
+		fmt.Fprintf(&p.b, "        %s\n            %s\n", p.paint(ansiDim, frame.Function), p.paint(ansiDim, location))
+		return
+	}
+
+	fmt.Fprintf(&p.b, "        %s\n            %s\n", p.paint(ansiBold+ansiYellow, frame.Function), location)
+	if !p.opts.NoSource {
+		p.source(frame)
+	}
+}
+
+// source writes the lines around frame, marking the frame's line.
+func (p *prettyPrinter) source(frame stacktrace) {
+	snippet := internal.SourceSnippet(p.sources, frame.File, frame.Line, p.opts.SourceLines)
+	if len(snippet) == 0 {
+		return
+	}
+
+	width := len(fmt.Sprint(snippet[len(snippet)-1].Number))
+	for _, line := range snippet {
+		if line.Current {
+			fmt.Fprintf(&p.b, "            %s\n", p.paint(ansiBold, fmt.Sprintf("> %*d | %s", width, line.Number, line.Text)))
+			continue
+		}
+		fmt.Fprintf(&p.b, "            %s\n", p.paint(ansiDim, fmt.Sprintf("  %*d | %s", width, line.Number, line.Text)))
+	}
+}
@@ -0,0 +1,111 @@
+package flooerr
+
+import (
+	"core-common-go/flooerr/internal"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func prettyTestError() error {
+	inner := Message("find failed").
+		WithCode("NOT_FOUND").
+		WithContext("table", "users").
+		WithContext("id", 42).
+		Error(errors.New("sql: no rows"), "find failed")
+	return Op("user.Get").WithSDC("trace_id", "t1").WithStackTrace(false).Wrap(inner, "get user")
+}
+
+func TestPretty_NoColor(t *testing.T) {
+	output := Pretty(prettyTestError(), PrettyOptions{Color: ColorNever})
+
+	if strings.Contains(output, "\x1b[") {
+		t.Error("Expected no ANSI escapes")
+	}
+
+	for _, expected := range []string{
+		"get user\n    op: user.Get\n    sdc:\n        trace_id  t1\n",
+		"caused by: find failed [NOT_FOUND]\n",
+		"    context:\n        id     42\n        table  users\n",
+		"caused by: sql: no rows\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestPretty_Source(t *testing.T) {
+	output := Pretty(prettyTestError(), PrettyOptions{Color: ColorNever, SourceLines: 1})
+
+	if !strings.Contains(output, "core-common-go/flooerr.prettyTestError\n") {
+		t.Fatalf("Expected the in-module frame:\n%s", output)
+	}
+
+	marked := regexp.MustCompile(`\n {12}> \d+ \| {9}Error\(errors\.New\("sql: no rows"\), "find failed"\)\n`)
+	if !strings.Contains(output, `|         WithContext("id", 42).`) || !marked.MatchString(output) {
+		t.Errorf("Expected a source snippet around the build site:\n%s", output)
+	}
+
+	output = Pretty(prettyTestError(), PrettyOptions{Color: ColorNever, NoSource: true})
+	if strings.Contains(output, " | ") {
+		t.Errorf("Expected no source snippets:\n%s", output)
+	}
+}
+
+func TestPretty_Color(t *testing.T) {
+	output := Pretty(prettyTestError(), PrettyOptions{Color: ColorAlways})
+
+	if !strings.Contains(output, ansiBold+ansiRed+"[NOT_FOUND]"+ansiReset) {
+		t.Errorf("Expected a colored code:\n%q", output)
+	}
+
+	if !strings.Contains(output, ansiBold+ansiYellow+"core-common-go/flooerr.prettyTestError"+ansiReset) {
+		t.Errorf("Expected the in-module frame to be highlighted:\n%q", output)
+	}
+
+}
+
+func TestPretty_DimsDependencyFrames(t *testing.T) {
+	p := prettyPrinter{color: true, sources: make(map[string][]string)}
+	p.frame(stacktrace{Function: "testing.tRunner", File: internal.FrameGOROOT() + "/src/testing/testing.go", Line: 1})
+	p.frame(stacktrace{Function: "github.com/lib/pq.(*conn).query", File: "/home/u/go/pkg/mod/github.com/lib/pq@v1.10.9/conn.go", Line: 1})
+
+	if output := p.b.String(); strings.Count(output, ansiDim) != 4 || strings.Contains(output, ansiYellow) {
+		t.Errorf("Expected runtime and dependency frames to be dimmed:\n%q", output)
+	}
+}
+
+func TestPretty_ModulePrefix(t *testing.T) {
+	output := Pretty(prettyTestError(), PrettyOptions{Color: ColorAlways, ModulePrefix: "example.com/"})
+
+	if strings.Contains(output, ansiYellow) {
+		t.Errorf("Expected no in-module frames outside the prefix:\n%q", output)
+	}
+}
+
+func TestPretty_AutoColor(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if output := Pretty(prettyTestError(), PrettyOptions{Output: file}); strings.Contains(output, "\x1b[") {
+		t.Error("Expected no colors when the output is not a terminal")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if (PrettyOptions{}).useColor() {
+		t.Error("Expected NO_COLOR to disable colors")
+	}
+}
+
+func TestPretty_Nil(t *testing.T) {
+	if Pretty(nil, PrettyOptions{}) != "" {
+		t.Error("Expected empty output for nil")
+	}
+}
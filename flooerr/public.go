@@ -0,0 +1,135 @@
+package flooerr
+
+import "sync/atomic"
+
+// DefaultPublicMessage is the public message of errors that have none and
+// whose codes have no fallback.
+const DefaultPublicMessage = "An internal error occurred."
+
+var publicFallbacks = NewCodeTable(map[ErrCode]string{
+	CodeNotFound:         "The requested resource was not found.",
+	CodeAlreadyExists:    "The resource already exists.",
+	CodePermissionDenied: "You do not have permission to perform this action.",
+	CodeInvalidArgument:  "The request is invalid.",
+	CodeInvalidJSON:      "The request body is not valid JSON.",
+	CodeCanceled:         "The request was canceled.",
+	CodeDeadlineExceeded: "The request timed out.",
+})
+
+// SetPublicFallback sets the public message of errors with code, or a code
+// under it, that were built without one. An empty message removes the
+// fallback.
+func SetPublicFallback(code ErrCode, message string) {
+	if message == "" {
+		publicFallbacks.Delete(code)
+		return
+	}
+	publicFallbacks.Set(code, message)
+}
+
+// PublicMessage returns the message of err that is safe to show to users:
+// the nearest one set with WithPublicMessage, else the fallback of the
+// nearest code that resolves to one, else DefaultPublicMessage. It never returns
+// the error text. It returns "" for a nil err.
+func PublicMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	for layer := range All(err) {
+		if message := publicOf(layer); message != "" {
+			return message
+		}
+	}
+
+	if message, ok := publicFallbacks.LookupErr(err); ok {
+		return message
+	}
+	return DefaultPublicMessage
+}
+
+// publicOf returns the public message of a single chain element, if it
+// has one.
+func publicOf(err error) string {
+	if layer, ok := err.(interface{ PublicMessage() string }); ok {
+		return layer.PublicMessage()
+	}
+	return ""
+}
+
+// Exposure selects what Public includes beyond the public message. Its
+// values combine with |.
+type Exposure uint32
+
+const (
+	// ExposeInternalMessage adds the error text.
+	ExposeInternalMessage Exposure = 1 << iota
+	// ExposeCauses adds the text of each cause.
+	ExposeCauses
+	// ExposeStack adds the stack trace.
+	ExposeStack
+)
+
+const (
+	// ExposeNone shows only the ID, code and public message. It is the
+	// default.
+	ExposeNone Exposure = 0
+	// ExposeAll shows everything, for development and internal callers.
+	ExposeAll = ExposeInternalMessage | ExposeCauses | ExposeStack
+)
+
+var exposure atomic.Uint32
+
+// SetExposure sets the global exposure policy and returns the previous one.
+func SetExposure(e Exposure) Exposure {
+	return Exposure(exposure.Swap(uint32(e)))
+}
+
+// PublicError is the view of an error that external renderers, such as
+// HTTP handlers, send to clients. Fields other than ID, Code and Message
+// are filled only when the exposure policy enables them.
+type PublicError struct {
+	ID      string       `json:"id,omitempty"`
+	Code    string       `json:"code,omitempty"`
+	Message string       `json:"message"`
+	Detail  string       `json:"detail,omitempty"`
+	Causes  []string     `json:"causes,omitempty"`
+	Stack   []stacktrace `json:"stack,omitempty"`
+}
+
+// Public returns the view of err to send to clients under the current
+// exposure policy:
+//
+//	flooerr.SetIDHeader(w.Header(), err)
+//	w.WriteHeader(status)
+//	json.NewEncoder(w).Encode(flooerr.Public(err))
+func Public(err error) PublicError {
+	if err == nil {
+		return PublicError{}
+	}
+	view := PublicError{
+		ID:      GetID(err),
+		Code:    EffectiveCode(err).String(),
+		Message: PublicMessage(err),
+	}
+
+	policy := Exposure(exposure.Load())
+	if policy&ExposeInternalMessage != 0 {
+		view.Detail = err.Error()
+	}
+	if policy&ExposeCauses != 0 {
+		for i, layer := range UnwrapChain(err) {
+			if i == 0 {
+				continue
+			}
+			if flooErr, ok := asLayer(layer); ok {
+				view.Causes = append(view.Causes, layerText(flooErr))
+			} else {
+				view.Causes = append(view.Causes, layer.Error())
+			}
+		}
+	}
+	if policy&ExposeStack != 0 {
+		view.Stack = GetStackTrace(err)
+	}
+	return view
+}
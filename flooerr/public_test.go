@@ -0,0 +1,127 @@
+package flooerr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type publicForeignError struct{}
+
+func (publicForeignError) Error() string {
+	return "pq: duplicate key value violates unique constraint users_email_key"
+}
+func (publicForeignError) PublicMessage() string { return "That email is already registered." }
+
+func TestPublicMessage(t *testing.T) {
+	internalErr := errors.New("pq: duplicate key value violates unique constraint users_email_key")
+	inner := Code("EMAIL_TAKEN").WithPublicMessage("That email is already registered.").Wrap(internalErr, "insert user failed")
+	outer := Wrap(inner, "signup failed")
+
+	if message := PublicMessage(outer); message != "That email is already registered." {
+		t.Errorf("Expected the nearest public message, got %q", message)
+	}
+
+	override := Code("SIGNUP").WithPublicMessage("Signup is unavailable.").Wrap(outer, "signup handler")
+	if message := PublicMessage(override); message != "Signup is unavailable." {
+		t.Errorf("Expected the outermost public message to win, got %q", message)
+	}
+
+	if message := PublicMessage(fmt.Errorf("wrapped: %w", publicForeignError{})); message != "That email is already registered." {
+		t.Errorf("Expected foreign errors implementing PublicMessage to be used, got %q", message)
+	}
+
+	if PublicMessage(nil) != "" {
+		t.Error("Expected an empty message for nil")
+	}
+}
+
+func TestPublicMessage_Fallback(t *testing.T) {
+	notFound := Wrap(Code(CodeNotFound).Error(nil, "user 42 not in table users"), "get user failed")
+	if message := PublicMessage(notFound); message != "The requested resource was not found." {
+		t.Errorf("Expected the NOT_FOUND fallback, got %q", message)
+	}
+
+	for _, err := range []error{
+		errors.New("pq: connection refused"),
+		Code("UNREGISTERED").Error(nil, "secret detail"),
+	} {
+		if message := PublicMessage(err); message != DefaultPublicMessage {
+			t.Errorf("%v: expected the default message, got %q", err, message)
+		}
+	}
+
+	SetPublicFallback("QUOTA", "You have reached your quota.")
+	defer SetPublicFallback("QUOTA", "")
+	if message := PublicMessage(Code("QUOTA").Error(nil, "tenant 7 over quota")); message != "You have reached your quota." {
+		t.Errorf("Expected the registered fallback, got %q", message)
+	}
+}
+
+func TestPublic_Exposure(t *testing.T) {
+	err := Code("EMAIL_TAKEN").
+		WithPublicMessage("That email is already registered.").
+		Wrap(errors.New("pq: duplicate key"), "insert user failed")
+
+	view := Public(err)
+	if view.Code != "EMAIL_TAKEN" || view.Message != "That email is already registered." || view.ID != GetID(err) {
+		t.Errorf("Unexpected view %+v", view)
+	}
+	data, _ := json.Marshal(view)
+	if strings.Contains(string(data), "pq:") || strings.Contains(string(data), "insert user") {
+		t.Errorf("Expected no internal detail by default, got %s", data)
+	}
+
+	previous := SetExposure(ExposeAll)
+	defer SetExposure(previous)
+	if previous != ExposeNone {
+		t.Errorf("Expected ExposeNone by default, got %d", previous)
+	}
+
+	view = Public(err)
+	if view.Detail != "insert user failed; caused by: pq: duplicate key" {
+		t.Errorf("Unexpected detail %q", view.Detail)
+	}
+	if len(view.Causes) != 1 || view.Causes[0] != "pq: duplicate key" {
+		t.Errorf("Unexpected causes %q", view.Causes)
+	}
+	if len(view.Stack) == 0 {
+		t.Error("Expected the stack to be exposed")
+	}
+
+	SetExposure(ExposeCauses)
+	if view = Public(err); view.Detail != "" || len(view.Stack) != 0 || len(view.Causes) != 1 {
+		t.Errorf("Expected only causes to be exposed, got %+v", view)
+	}
+
+	if view := Public(Wrap(err, "handler failed")); view.Code != "EMAIL_TAKEN" {
+		t.Errorf("Expected the code inherited from the cause, got %q", view.Code)
+	}
+
+	if view := Public(nil); view.Message != "" || view.Code != "" {
+		t.Errorf("Expected an empty view for nil, got %+v", view)
+	}
+}
+
+func TestPublicMessage_Encodings(t *testing.T) {
+	err := Code("EMAIL_TAKEN").WithPublicMessage("Email taken.").WithStackTrace(false).Error(nil, "insert failed")
+
+	binary, _ := MarshalBinary(err)
+	fromBinary, decodeErr := UnmarshalBinary(binary)
+	if decodeErr != nil || PublicMessage(fromBinary) != "Email taken." {
+		t.Errorf("Expected the public message to survive binary encoding, got %v", decodeErr)
+	}
+
+	data, _ := json.Marshal(err)
+	fromJSON, decodeErr := DecodeJSON(data)
+	if decodeErr != nil || PublicMessage(fromJSON) != "Email taken." {
+		t.Errorf("Expected the public message to survive JSON encoding, got %s", data)
+	}
+
+	infos, parseErr := ParseText(fmt.Sprintf("%+v", err))
+	if parseErr != nil || infos[0].PublicMessage != "Email taken." {
+		t.Errorf("Expected the public message in the text rendering, got %+v", infos)
+	}
+}
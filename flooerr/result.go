@@ -0,0 +1,134 @@
+package flooerr
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// CodePanic is the code of errors built from a recovered panic.
+	CodePanic ErrCode = "PANIC"
+	// CodeMultiple is the code of errors that aggregate several failures.
+	CodeMultiple ErrCode = "MULTIPLE_ERRORS"
+)
+
+// Result holds either a value or an error. The zero Result is Ok with the
+// zero value of T.
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Ok returns a successful Result holding value.
+func Ok[T any](value T) Result[T] {
+	return Result[T]{value: value}
+}
+
+// Err returns a failed Result holding err. A nil err yields Ok with the zero
+// value of T.
+func Err[T any](err error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// Try runs fn and captures its value and error. A panic in fn is recovered
+// and returned as a FlooErr with CodePanic; a panic with an error value
+// keeps that error as the cause.
+func Try[T any](fn func() (T, error)) (result Result[T]) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result = Result[T]{err: panicError(recovered)}
+		}
+	}()
+
+	value, err := fn()
+	return Result[T]{value: value, err: err}
+}
+
+// panicError converts a recovered panic value into a FlooErr. It must be
+// called from the deferred function so the stack trace starts at the panic.
+func panicError(recovered any) error {
+	cause, _ := recovered.(error)
+	message := fmt.Sprintf("panic: %v", recovered)
+	return Message(message).
+		WithCode(CodePanic.String()).
+		WithContext("panic", recovered).
+		BuildSkip(1, cause, message)
+}
+
+// IsOk reports whether the Result holds a value.
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// Value returns the value, which is the zero value of T for failed Results.
+func (r Result[T]) Value() T {
+	return r.value
+}
+
+// Err returns the error, or nil for successful Results.
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Unwrap returns the value and the error as a conventional pair.
+func (r Result[T]) Unwrap() (T, error) {
+	return r.value, r.err
+}
+
+// OrElse returns r when it is Ok, and fn(r.Err()) otherwise.
+func (r Result[T]) OrElse(fn func(error) Result[T]) Result[T] {
+	if r.err == nil {
+		return r
+	}
+	return fn(r.err)
+}
+
+// Map applies fn to the value of a successful Result. Failed Results pass
+// their error through unchanged.
+func Map[T, U any](r Result[T], fn func(T) U) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return Result[U]{value: fn(r.value)}
+}
+
+// AndThen chains a fallible step after a successful Result. Failed Results
+// pass their error through unchanged.
+func AndThen[T, U any](r Result[T], fn func(T) Result[U]) Result[U] {
+	if r.err != nil {
+		return Result[U]{err: r.err}
+	}
+	return fn(r.value)
+}
+
+// Collect returns the values of the successful results, in order, and an
+// error aggregating every failure. The aggregate is a FlooErr with
+// CodeMultiple whose cause joins the individual errors, so errors.Is and
+// errors.As see each of them; the indexes of the failed results are stored
+// in its context under "failed_indexes". Collect returns a nil error when
+// every result is Ok.
+func Collect[T any](results []Result[T]) ([]T, error) {
+	values := make([]T, 0, len(results))
+	var errs []error
+	var indexes []int
+
+	for i, result := range results {
+		if result.err != nil {
+			errs = append(errs, result.err)
+			indexes = append(indexes, i)
+			continue
+		}
+		values = append(values, result.value)
+	}
+
+	if len(errs) == 0 {
+		return values, nil
+	}
+
+	message := fmt.Sprintf("%d of %d results failed", len(errs), len(results))
+	return values, Message(message).
+		WithCode(CodeMultiple.String()).
+		WithContext("failed_indexes", indexes).
+		WithStackTrace(false).
+		Build(errors.Join(errs...), message)
+}
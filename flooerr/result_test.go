@@ -0,0 +1,151 @@
+package flooerr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestResult_Ok(t *testing.T) {
+	r := Ok(42)
+
+	if !r.IsOk() || r.Value() != 42 || r.Err() != nil {
+		t.Errorf("Unexpected result %+v", r)
+	}
+
+	value, err := r.Unwrap()
+	if value != 42 || err != nil {
+		t.Errorf("Expected (42, nil), got (%d, %v)", value, err)
+	}
+}
+
+func TestResult_Err(t *testing.T) {
+	failure := Code("NOT_FOUND").Error(nil, "missing")
+	r := Err[int](failure)
+
+	if r.IsOk() || r.Value() != 0 || r.Err() != failure {
+		t.Errorf("Unexpected result %+v", r)
+	}
+
+	if !Err[int](nil).IsOk() {
+		t.Error("Expected Err(nil) to be Ok")
+	}
+}
+
+func TestMap(t *testing.T) {
+	r := Map(Ok(21), func(v int) string { return strconv.Itoa(v * 2) })
+	if r.Value() != "42" {
+		t.Errorf("Expected '42', got '%s'", r.Value())
+	}
+
+	failure := errors.New("failed")
+	called := false
+	r = Map(Err[int](failure), func(v int) string { called = true; return "" })
+	if called || r.Err() != failure {
+		t.Error("Expected Map to pass the error through")
+	}
+}
+
+func TestAndThen(t *testing.T) {
+	parse := func(s string) Result[int] {
+		return Try(func() (int, error) { return strconv.Atoi(s) })
+	}
+
+	if r := AndThen(Ok("42"), parse); r.Value() != 42 {
+		t.Errorf("Expected 42, got %d", r.Value())
+	}
+
+	if r := AndThen(Ok("x"), parse); r.IsOk() {
+		t.Error("Expected parse failure")
+	}
+
+	failure := errors.New("failed")
+	if r := AndThen(Err[string](failure), parse); r.Err() != failure {
+		t.Error("Expected AndThen to pass the error through")
+	}
+}
+
+func TestResult_OrElse(t *testing.T) {
+	fallback := func(err error) Result[int] { return Ok(-1) }
+
+	if r := Ok(1).OrElse(fallback); r.Value() != 1 {
+		t.Errorf("Expected 1, got %d", r.Value())
+	}
+
+	if r := Err[int](errors.New("failed")).OrElse(fallback); r.Value() != -1 {
+		t.Errorf("Expected -1, got %d", r.Value())
+	}
+}
+
+func TestTry_Panic(t *testing.T) {
+	r := Try(func() (int, error) { panic("boom") })
+
+	if r.IsOk() {
+		t.Fatal("Expected panic to become an error")
+	}
+
+	if !HasCode(r.Err(), CodePanic.String()) {
+		t.Errorf("Expected code %s, got '%s'", CodePanic, GetCodeString(r.Err()))
+	}
+
+	if r.Err().Error() != "panic: boom" || GetContextValue(r.Err(), "panic") != "boom" {
+		t.Errorf("Unexpected error %v", r.Err())
+	}
+
+	found := false
+	for frame := range Frames(r.Err()) {
+		if strings.HasSuffix(frame.Function, "TestTry_Panic.func1") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected the stack trace to include the panicking function")
+	}
+}
+
+func TestTry_PanicWithError(t *testing.T) {
+	cause := errors.New("boom")
+	r := Try(func() (int, error) { panic(cause) })
+
+	if !errors.Is(r.Err(), cause) {
+		t.Error("Expected the panic error to be kept as cause")
+	}
+}
+
+func TestCollect(t *testing.T) {
+	notFound := Code("NOT_FOUND").Error(nil, "missing")
+	invalid := errors.New("invalid")
+	results := []Result[int]{Ok(1), Err[int](notFound), Ok(3), Err[int](invalid)}
+
+	values, err := Collect(results)
+
+	if fmt.Sprint(values) != "[1 3]" {
+		t.Errorf("Expected [1 3], got %v", values)
+	}
+
+	if !HasCode(err, CodeMultiple.String()) {
+		t.Errorf("Expected code %s, got '%s'", CodeMultiple, GetCodeString(err))
+	}
+
+	if !errors.Is(err, notFound) || !errors.Is(err, invalid) {
+		t.Error("Expected every failure to be reachable with errors.Is")
+	}
+
+	if fmt.Sprint(GetContextValue(err, "failed_indexes")) != "[1 3]" {
+		t.Errorf("Expected failed_indexes [1 3], got %v", GetContextValue(err, "failed_indexes"))
+	}
+
+	if GetMessage(err) != "2 of 4 results failed" {
+		t.Errorf("Unexpected message '%s'", GetMessage(err))
+	}
+}
+
+func TestCollect_AllOk(t *testing.T) {
+	values, err := Collect([]Result[string]{Ok("a"), Ok("b")})
+
+	if err != nil || len(values) != 2 {
+		t.Errorf("Expected 2 values and no error, got %v %v", values, err)
+	}
+}
@@ -0,0 +1,23 @@
+package flooerr
+
+// Sentinel creates a stackless FlooErr meant to be declared once at package
+// level and compared with errors.Is. Any FlooErr in a chain carrying the same
+// non-empty code matches the sentinel, so errors built by constructors only
+// need the code to be recognised.
+func Sentinel(code ErrCode, message string) error {
+	return &err{
+		message:    message,
+		errMessage: message,
+		code:       code,
+		sentinel:   true,
+	}
+}
+
+// Is reports whether target is a sentinel with the same code as e.
+func (e *err) Is(target error) bool {
+	t, ok := target.(*err)
+	if !ok || !t.sentinel {
+		return false
+	}
+	return e.code != "" && e.code == t.code
+}
@@ -0,0 +1,63 @@
+package flooerr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSentinel(t *testing.T) {
+	sentinel := Sentinel("USER_NOT_FOUND", "user not found")
+
+	flooErr, ok := sentinel.(FlooErr)
+	if !ok {
+		t.Fatal("Expected FlooErr interface")
+	}
+
+	if flooErr.Code() != "USER_NOT_FOUND" {
+		t.Errorf("Expected code 'USER_NOT_FOUND', got '%s'", flooErr.Code())
+	}
+
+	if sentinel.Error() != "user not found" {
+		t.Errorf("Expected 'user not found', got '%s'", sentinel.Error())
+	}
+
+	if len(flooErr.StackTrace()) != 0 {
+		t.Error("Expected sentinel to have no stack trace")
+	}
+}
+
+func TestSentinel_ErrorsIs(t *testing.T) {
+	sentinel := Sentinel("USER_NOT_FOUND", "user not found")
+
+	err := Code("USER_NOT_FOUND").Error(nil, "user 42 not found")
+	if !errors.Is(err, sentinel) {
+		t.Error("Expected error with same code to match sentinel")
+	}
+
+	wrapped := Wrap(err, "handler failed")
+	if !errors.Is(wrapped, sentinel) {
+		t.Error("Expected wrapped error to match sentinel")
+	}
+
+	other := Code("OTHER").Error(nil, "other")
+	if errors.Is(other, sentinel) {
+		t.Error("Expected error with different code not to match sentinel")
+	}
+}
+
+func TestSentinel_EmptyCode(t *testing.T) {
+	sentinel := Sentinel("", "empty")
+
+	if errors.Is(Error("plain"), sentinel) {
+		t.Error("Expected empty code never to match")
+	}
+}
+
+func TestIs_NonSentinelTarget(t *testing.T) {
+	first := Code("SAME").Error(nil, "first")
+	second := Code("SAME").Error(nil, "second")
+
+	if errors.Is(first, second) {
+		t.Error("Expected non-sentinel errors to compare by identity")
+	}
+}
@@ -0,0 +1,272 @@
+package flooerr
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The text rendering written by %+v is stable: tooling may rely on it, and
+// ParseText reads it back. A chain renders as one block per layer, outermost
+// first:
+//
+//	<error text>
+//	    id: <error ID>
+//	    op: <operation>
+//	    code: <code>
+//	    message: <message, when it differs from the error text>
+//	    public: <public message>
+//	    context:
+//	        <key>: <value>
+//	    sdc:
+//	        <key>: <value>
+//	    stack:
+//	        <function>
+//	            <file>:<line>
+//	caused by: <error text of the next layer>
+//	    ...
+//
+// Every field line is optional and appears in this order. Context and SDC
+// keys are sorted. Layers that are not FlooErrs have no field lines. Inside
+// texts and values, backslashes are written as `\\` and newlines and
+// carriage returns as `\n` and `\r`, so every line of the rendering is one
+// line of the block. A layer with an empty text is written as `\(empty)`,
+// which no escaped text can spell. New fields may be added in later
+// versions; readers should skip field names they do not know.
+
+// textEmpty is the header of a layer whose text is empty.
+const textEmpty = `\(empty)`
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+// escapeLine keeps s on one line of the text rendering.
+func escapeLine(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// escapeText is escapeLine for the header of a layer, which must not be
+// blank.
+func escapeText(s string) string {
+	if s == "" {
+		return textEmpty
+	}
+	return escapeLine(s)
+}
+
+// unescapeLine reverses escapeLine. Unknown escapes are kept as written.
+func unescapeLine(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '\\':
+			b.WriteByte('\\')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
+
+// unescapeText reverses escapeText.
+func unescapeText(s string) string {
+	if s == textEmpty {
+		return ""
+	}
+	return unescapeLine(s)
+}
+
+const (
+	textCause = "caused by: "
+	textField = "    "
+	textItem  = "        "
+	textLoc   = "            "
+)
+
+var (
+	// textStructure finds the first line after the header of a rendering and
+	// captures the log prefix in front of it.
+	textStructure = regexp.MustCompile(`^(.*?)(?:caused by: |    (?:id|op|code|message|public|context|sdc|stack):)`)
+	// textLogPrefix matches the timestamps, levels and file:line markers
+	// loggers put in front of a line.
+	textLogPrefix = regexp.MustCompile(`^(?:(?:\d{4}[-/]\d{2}[-/]\d{2}(?:[T ]\d{2}:\d{2}:\d{2}\S*)?|\d{2}:\d{2}:\d{2}\S*|\[?(?:DEBUG|INFO|WARN|WARNING|ERROR|ERR|FATAL|PANIC)\]?:?|\S+\.go:\d+:)\s+)+`)
+)
+
+// ParseText reconstructs the layers of a chain from its %+v rendering, as
+// ParseChain would return them for the original error. It is meant for
+// plain-text logs:
+//
+//   - A log prefix repeated on every line, such as a timestamp or a container
+//     name, is stripped. When only the first line carries a prefix, leading
+//     timestamps, levels and file:line markers are stripped from it.
+//   - Lines before the rendering are skipped, and parsing stops at the first
+//     blank line or line that does not fit the rendering.
+//   - A truncated trailing frame keeps what is left of it: a function without
+//     a location, or a file without a line.
+//
+// Escaped texts and values are unescaped. Context values come back as
+// strings, Message is set only for layers with a message line, and Time is
+// never set. Layers without field lines are reported as foreign errors.
+// ParseText fails only when text holds no error.
+func ParseText(text string) ([]ErrorInfo, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	header, prefix := -1, ""
+	for i := 1; i < len(lines); i++ {
+		if match := textStructure.FindStringSubmatch(lines[i]); match != nil && strings.TrimSpace(lines[i-1]) != "" {
+			header, prefix = i-1, match[1]
+			break
+		}
+	}
+	if header < 0 {
+		for i, line := range lines {
+			if strings.TrimSpace(line) != "" {
+				header = i
+				break
+			}
+		}
+	}
+	if header < 0 {
+		return nil, errors.New("flooerr: no error found in text")
+	}
+
+	first := stripTextPrefix(lines[header], prefix)
+	if prefix == "" {
+		first = textLogPrefix.ReplaceAllString(first, "")
+	}
+	layers := []*textLayer{{err: &err{errMessage: unescapeText(first)}}}
+	current := layers[0]
+	section := ""
+
+	for _, line := range lines[header+1:] {
+		line = stripTextPrefix(line, prefix)
+		switch {
+		case strings.HasPrefix(line, textCause):
+			current = &textLayer{err: &err{errMessage: unescapeText(strings.TrimPrefix(line, textCause))}}
+			layers = append(layers, current)
+			section = ""
+		case strings.HasPrefix(line, textLoc):
+			if section == "stack" && len(current.stackTrace) > 0 {
+				setFrameLocation(&current.stackTrace[len(current.stackTrace)-1], line[len(textLoc):])
+			}
+		case strings.HasPrefix(line, textItem):
+			parseTextItem(current, section, line[len(textItem):])
+		case strings.HasPrefix(line, textField) && strings.TrimSpace(line) != "":
+			section = parseTextField(current, line[len(textField):])
+		default:
+			return textInfos(layers), nil
+		}
+	}
+	return textInfos(layers), nil
+}
+
+// stripTextPrefix removes the log prefix of a line. Prefixes that vary from
+// line to line, like timestamps, are assumed to keep their width.
+func stripTextPrefix(line, prefix string) string {
+	line = strings.TrimRight(line, "\r")
+	if strings.HasPrefix(line, prefix) {
+		return line[len(prefix):]
+	}
+	if len(line) < len(prefix) {
+		return ""
+	}
+	return line[len(prefix):]
+}
+
+// textLayer is a layer being parsed. Layers with at least one field line,
+// known or not, are FlooErrs.
+type textLayer struct {
+	*err
+	fields bool
+}
+
+// parseTextField applies a field line to layer and returns the section it
+// opens, if any.
+func parseTextField(layer *textLayer, field string) string {
+	layer.fields = true
+	e := layer.err
+	name, value, _ := strings.Cut(field, ":")
+	value = unescapeLine(strings.TrimPrefix(value, " "))
+	switch name {
+	case "id":
+		e.id, _ = parseErrorID(value)
+	case "op":
+		e.op = value
+	case "code":
+		e.code = ErrCode(value)
+	case "message":
+		e.message = value
+	case "public":
+		e.publicMessage = value
+	case "context", "sdc", "stack":
+		return name
+	}
+	return ""
+}
+
+// parseTextItem applies a line of the context, SDC or stack section to
+// layer.
+func parseTextItem(layer *textLayer, section, item string) {
+	e := layer.err
+	switch section {
+	case "context":
+		key, value, _ := strings.Cut(item, ": ")
+		if e.context == nil {
+			e.context = map[string]any{}
+		}
+		e.context[unescapeLine(key)] = unescapeLine(value)
+	case "sdc":
+		key, value, _ := strings.Cut(item, ": ")
+		if e.sdc == nil {
+			e.sdc = map[string]string{}
+		}
+		e.sdc[unescapeLine(key)] = unescapeLine(value)
+	case "stack":
+		e.stackTrace = append(e.stackTrace, stacktrace{Function: item})
+	}
+}
+
+// setFrameLocation parses "file:line" into frame. A location cut short
+// keeps its file and leaves the line at zero.
+func setFrameLocation(frame *stacktrace, location string) {
+	frame.File = location
+	if i := strings.LastIndex(location, ":"); i > 0 {
+		if line, convErr := strconv.Atoi(location[i+1:]); convErr == nil {
+			frame.File, frame.Line = location[:i], line
+		}
+	}
+}
+
+// textInfos links the parsed layers into a chain and returns its ErrorInfo.
+func textInfos(parsed []*textLayer) []ErrorInfo {
+	layers := make([]error, len(parsed))
+	for i, layer := range parsed {
+		if layer.fields {
+			layers[i] = layer.err
+		} else {
+			layers[i] = &decodedError{message: layer.errMessage}
+		}
+	}
+	for i := len(layers) - 2; i >= 0; i-- {
+		switch layer := layers[i].(type) {
+		case *err:
+			layer.cause = layers[i+1]
+		case *decodedError:
+			layer.cause = layers[i+1]
+		}
+	}
+	return ParseChain(layers[0])
+}
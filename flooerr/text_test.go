@@ -0,0 +1,209 @@
+package flooerr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseText_RoundTrip(t *testing.T) {
+	inner := Message("find failed").
+		WithCode("NOT_FOUND").
+		WithOp("repo.Find").
+		WithContext("table", "users").
+		Error(errors.New("sql: no rows"), "find failed")
+	outer := Message("get user failed").
+		WithCode("USER_ERR").
+		WithContext("id", 42).
+		WithSDC("request_id", "req_1").
+		Error(inner, "get user failed")
+
+	infos, err := ParseText(fmt.Sprintf("%+v", outer))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := ParseChain(outer)
+	if len(infos) != len(expected) {
+		t.Fatalf("Expected %d layers, got %d", len(expected), len(infos))
+	}
+	for i, info := range infos {
+		want := expected[i]
+		if info.IsFlooErr != want.IsFlooErr || info.Code != want.Code || info.ErrorMsg != want.ErrorMsg ||
+			info.ID != want.ID || info.Op != want.Op {
+			t.Errorf("Layer %d: expected %+v, got %+v", i, want, info)
+		}
+		if !reflect.DeepEqual(info.StackTrace, want.StackTrace) {
+			t.Errorf("Layer %d: expected stack %v, got %v", i, want.StackTrace, info.StackTrace)
+		}
+		if len(info.SDC) != len(want.SDC) {
+			t.Errorf("Layer %d: expected SDC %v, got %v", i, want.SDC, info.SDC)
+		}
+	}
+
+	if infos[0].Context["id"] != "42" || infos[0].SDC["request_id"] != "req_1" {
+		t.Errorf("Unexpected context %v and SDC %v", infos[0].Context, infos[0].SDC)
+	}
+	if infos[1].Context["table"] != "users" {
+		t.Errorf("Unexpected context %v", infos[1].Context)
+	}
+}
+
+const textSample = `get user failed
+    code: USER_ERR
+    context:
+        id: 42
+    stack:
+        main.getUser
+            /app/main.go:42
+caused by: sql: no rows
+`
+
+func TestParseText_LogPrefixes(t *testing.T) {
+	var everyLine strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(textSample, "\n"), "\n") {
+		fmt.Fprintf(&everyLine, "2026-03-01T10:00:0%dZ api-7f9c | %s\n", i, line)
+	}
+
+	tests := map[string]string{
+		"none":        textSample,
+		"every line":  everyLine.String(),
+		"first line":  "2026/03/01 10:00:00 main.go:17: " + textSample,
+		"level":       "10:00:00.123 [ERROR] " + textSample,
+		"surrounding": "starting\nlistening on :8080\n" + textSample + "\nshutting down\n",
+		"windows":     strings.ReplaceAll(textSample, "\n", "\r\n"),
+	}
+
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			infos, err := ParseText(text)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(infos) != 2 {
+				t.Fatalf("Expected 2 layers, got %+v", infos)
+			}
+			if infos[0].Code != "USER_ERR" || infos[0].ErrorMsg != "get user failed; caused by: sql: no rows" {
+				t.Errorf("Unexpected first layer %+v", infos[0])
+			}
+			if infos[0].Context["id"] != "42" {
+				t.Errorf("Unexpected context %v", infos[0].Context)
+			}
+			expectedStack := []stacktrace{{Function: "main.getUser", File: "/app/main.go", Line: 42}}
+			if !reflect.DeepEqual(infos[0].StackTrace, expectedStack) {
+				t.Errorf("Unexpected stack %v", infos[0].StackTrace)
+			}
+			if infos[1].IsFlooErr || infos[1].ErrorMsg != "sql: no rows" {
+				t.Errorf("Unexpected second layer %+v", infos[1])
+			}
+		})
+	}
+}
+
+func TestParseText_TruncatedFrames(t *testing.T) {
+	text := `query failed
+    code: DB_ERR
+    stack:
+        db.Query
+            /app/db/query.go:18
+        db.Exec
+            /app/db/ex`
+
+	infos, err := ParseText(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []stacktrace{
+		{Function: "db.Query", File: "/app/db/query.go", Line: 18},
+		{Function: "db.Exec", File: "/app/db/ex"},
+	}
+	if !reflect.DeepEqual(infos[0].StackTrace, expected) {
+		t.Errorf("Unexpected stack %v", infos[0].StackTrace)
+	}
+
+	infos, _ = ParseText(text[:strings.LastIndex(text, "\n")])
+	if last := infos[0].StackTrace[1]; last.Function != "db.Exec" || last.File != "" {
+		t.Errorf("Expected a frame without location, got %v", last)
+	}
+}
+
+func TestParseText_Escaping(t *testing.T) {
+	err := Code("MULTI").
+		WithContext("query", "SELECT 1\nFROM t").
+		WithContext("path", `C:\new\table`).
+		WithSDC("raw", `a\nb`).
+		WithStackTrace(false).
+		Error(nil, "line one\r\nline two \\n")
+
+	detail := fmt.Sprintf("%+v", err)
+	if strings.Count(detail, "\n") != 8 {
+		t.Errorf("Expected one line per field, got:\n%s", detail)
+	}
+
+	infos, parseErr := ParseText(detail)
+	if parseErr != nil {
+		t.Fatalf("Unexpected error: %v", parseErr)
+	}
+	if infos[0].ErrorMsg != err.Error() {
+		t.Errorf("Expected error text %q, got %q", err.Error(), infos[0].ErrorMsg)
+	}
+	if infos[0].Context["query"] != "SELECT 1\nFROM t" || infos[0].Context["path"] != `C:\new\table` {
+		t.Errorf("Unexpected context %q", infos[0].Context)
+	}
+	if infos[0].SDC["raw"] != `a\nb` {
+		t.Errorf("Unexpected SDC %q", infos[0].SDC)
+	}
+}
+
+func TestParseText_EmptyText(t *testing.T) {
+	inner := Code("NOT_FOUND").Error(nil, "")
+	outer := Wrap(Code("WRAPPED").Wrap(inner, ""), "")
+
+	detail := fmt.Sprintf("%+v", outer)
+	if strings.Contains(detail, "\n\n") {
+		t.Errorf("Expected no blank header, got:\n%s", detail)
+	}
+
+	infos, parseErr := ParseText(detail)
+	if parseErr != nil {
+		t.Fatalf("Unexpected error: %v", parseErr)
+	}
+	expected := ParseChain(outer)
+	if len(infos) != len(expected) {
+		t.Fatalf("Expected %d layers, got %d:\n%s", len(expected), len(infos), detail)
+	}
+	for i, info := range infos {
+		want := expected[i]
+		if info.ErrorMsg != want.ErrorMsg || info.Text != "" || info.ID != want.ID || info.Code != want.Code {
+			t.Errorf("Layer %d: expected %+v, got %+v", i, want, info)
+		}
+		if !reflect.DeepEqual(info.StackTrace, want.StackTrace) {
+			t.Errorf("Layer %d: expected stack %v, got %v", i, want.StackTrace, info.StackTrace)
+		}
+	}
+
+	infos, _ = ParseText(fmt.Sprintf("%+v", Wrap(errors.New(""), `\(empty)`)))
+	if len(infos) != 2 || infos[0].Text != `\(empty)` || infos[1].ErrorMsg != "" {
+		t.Errorf("Expected the placeholder to stay distinct from its text, got %+v", infos)
+	}
+}
+
+func TestParseText_Layers(t *testing.T) {
+	infos, err := ParseText("connection reset\n")
+	if err != nil || len(infos) != 1 || infos[0].IsFlooErr || infos[0].ErrorMsg != "connection reset" {
+		t.Errorf("Unexpected result %+v, %v", infos, err)
+	}
+
+	infos, err = ParseText("failed\n    color: red\n    code: X\n    message: try again\n")
+	if err != nil || len(infos) != 1 || !infos[0].IsFlooErr || infos[0].Code != "X" || infos[0].Message != "try again" {
+		t.Errorf("Expected unknown fields to be skipped, got %+v, %v", infos, err)
+	}
+
+	for _, text := range []string{"", "\n  \n"} {
+		if _, err := ParseText(text); err == nil {
+			t.Errorf("Expected an error for %q", text)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package flooerr
+
+import (
+	"core-common-go/flooerr/internal"
+	"fmt"
+	"strings"
+)
+
+// StackMode selects how much of the stack each build records.
+type StackMode = internal.StackMode
+
+const (
+	// StackFull records up to 15 frames at every build. It is the default.
+	StackFull = internal.StackFull
+	// StackReturn records only the call site of every build, so a chain of
+	// wraps yields a return trace showing where the error travelled.
+	StackReturn = internal.StackReturn
+	// StackNone records no frames.
+	StackNone = internal.StackNone
+)
+
+// SetStackMode sets the global stack mode and returns the previous one.
+// WithStackTrace(false) still disables frames for a single build.
+func SetStackMode(mode StackMode) StackMode {
+	return internal.SetStackMode(mode)
+}
+
+// ReturnTrace returns the build site of every FlooErr layer, starting at
+// the innermost layer where the error originated and ending at the
+// outermost wrap. Layers without frames are skipped.
+func ReturnTrace(err error) []stacktrace {
+	var sites []stacktrace
+	for flooErr := range FlooErrs(err) {
+		if stack := flooErr.StackTrace(); len(stack) > 0 {
+			sites = append(sites, stack[0])
+		}
+	}
+	for i, j := 0, len(sites)-1; i < j; i, j = i+1, j-1 {
+		sites[i], sites[j] = sites[j], sites[i]
+	}
+	return sites
+}
+
+// Trace renders where err came from and how it travelled. The origin
+// section holds every frame recorded by the innermost layer with a stack,
+// which is the full stack in StackFull mode; the return trace lists the
+// build site of each enclosing layer, in the order the error was returned:
+//
+//	api.GetUser: repo.Find: not found
+//	origin:
+//	    app/repo.Find
+//	        /app/repo/repo.go:12
+//	    app/user.(*Service).Get
+//	        /app/user/service.go:30
+//	return trace:
+//	    app/user.(*Service).Get
+//	        /app/user/service.go:31
+//	    app/api.GetUser
+//	        /app/api/user.go:18
+func Trace(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var layers []FlooErr
+	for flooErr := range FlooErrs(err) {
+		layers = append(layers, flooErr)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", err.Error())
+
+	origin := -1
+	for i := len(layers) - 1; i >= 0; i-- {
+		if len(layers[i].StackTrace()) > 0 {
+			origin = i
+			break
+		}
+	}
+	if origin < 0 {
+		return b.String()
+	}
+
+	b.WriteString("origin:\n")
+	writeFrames(&b, layers[origin].StackTrace())
+
+	var returns []stacktrace
+	for i := origin - 1; i >= 0; i-- {
+		if stack := layers[i].StackTrace(); len(stack) > 0 {
+			returns = append(returns, stack[0])
+		}
+	}
+	if len(returns) > 0 {
+		b.WriteString("return trace:\n")
+		writeFrames(&b, returns)
+	}
+	return b.String()
+}
+
+func writeFrames(b *strings.Builder, frames []stacktrace) {
+	for _, frame := range frames {
+		fmt.Fprintf(b, "    %s\n        %s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+}
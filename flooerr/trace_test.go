@@ -0,0 +1,146 @@
+package flooerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func traceRepo() error {
+	return Code("NOT_FOUND").Error(nil, "not found")
+}
+
+func traceService() error {
+	return Wrap(traceRepo(), "get user")
+}
+
+func traceHandler() error {
+	return Wrap(traceService(), "handle request")
+}
+
+func functionNames(frames []stacktrace) []string {
+	var names []string
+	for _, frame := range frames {
+		names = append(names, frame.Function[strings.LastIndex(frame.Function, ".")+1:])
+	}
+	return names
+}
+
+func TestSetStackMode_Return(t *testing.T) {
+	defer SetStackMode(SetStackMode(StackReturn))
+
+	err := traceHandler()
+
+	for flooErr := range FlooErrs(err) {
+		if len(flooErr.StackTrace()) != 1 {
+			t.Errorf("Expected 1 frame per layer, got %d", len(flooErr.StackTrace()))
+		}
+	}
+
+	names := strings.Join(functionNames(ReturnTrace(err)), " ")
+	if names != "traceRepo traceService traceHandler" {
+		t.Errorf("Unexpected return trace '%s'", names)
+	}
+}
+
+func traceBuildRepo() error {
+	return Code("NOT_FOUND").Build(nil, "not found")
+}
+
+func traceBuildService() error {
+	return Message("get user").Build(traceBuildRepo(), "")
+}
+
+func TestSetStackMode_ReturnDirectBuild(t *testing.T) {
+	defer SetStackMode(SetStackMode(StackReturn))
+
+	names := strings.Join(functionNames(ReturnTrace(traceBuildService())), " ")
+	if names != "traceBuildRepo traceBuildService" {
+		t.Errorf("Unexpected return trace '%s'", names)
+	}
+}
+
+func TestBuild_StackStart(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"Build", Code("X").Build(nil, "direct")},
+		{"BuildSkip", func() error { return Code("X").BuildSkip(0, nil, "helper") }()},
+		{"Wrap", Wrap(errors.New("cause"), "wrapped")},
+		{"ErrProps.Wrap", Code("X").Wrap(errors.New("cause"), "wrapped")},
+		{"ErrProps.Errorf", Code("X").Errorf("failed %d", 1)},
+	}
+	for _, tt := range tests {
+		stackTrace := GetStackTrace(tt.err)
+		if len(stackTrace) == 0 || stackTrace[0].Function != "core-common-go/flooerr.TestBuild_StackStart" {
+			t.Errorf("%s: expected the stack to start in the test, got %v", tt.name, functionNames(stackTrace))
+		}
+	}
+}
+
+func TestSetStackMode_None(t *testing.T) {
+	defer SetStackMode(SetStackMode(StackNone))
+
+	err := traceHandler()
+	if len(ReturnTrace(err)) != 0 {
+		t.Error("Expected no frames in StackNone mode")
+	}
+
+	if Trace(err) != err.Error()+"\n" {
+		t.Errorf("Expected only the message, got %q", Trace(err))
+	}
+}
+
+func TestReturnTrace_FullStack(t *testing.T) {
+	err := Wrap(fmt.Errorf("layer: %w", traceService()), "top")
+
+	names := strings.Join(functionNames(ReturnTrace(err)), " ")
+	if names != "traceRepo traceService TestReturnTrace_FullStack" {
+		t.Errorf("Unexpected return trace '%s'", names)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	err := traceHandler()
+	trace := Trace(err)
+
+	origin := strings.Index(trace, "origin:\n")
+	returns := strings.Index(trace, "return trace:\n")
+	if !strings.HasPrefix(trace, err.Error()+"\n") || origin < 0 || returns < origin {
+		t.Fatalf("Unexpected trace:\n%s", trace)
+	}
+
+	// The origin holds the full stack of the innermost layer.
+	if !strings.Contains(trace[origin:returns], "traceRepo") || !strings.Contains(trace[origin:returns], "TestTrace") {
+		t.Errorf("Expected the full origin stack:\n%s", trace)
+	}
+
+	returnSection := trace[returns:]
+	if strings.Index(returnSection, "traceService") > strings.Index(returnSection, "traceHandler") {
+		t.Errorf("Expected returns in order:\n%s", trace)
+	}
+}
+
+func TestTrace_Mixed(t *testing.T) {
+	err := traceRepo()
+	previous := SetStackMode(StackReturn)
+	err = Wrap(err, "wrapped")
+	SetStackMode(previous)
+
+	trace := Trace(err)
+	if !strings.Contains(trace, "return trace:\n    core-common-go/flooerr.TestTrace_Mixed\n") {
+		t.Errorf("Expected the wrap site in the return trace:\n%s", trace)
+	}
+}
+
+func TestTrace_Nil(t *testing.T) {
+	if Trace(nil) != "" {
+		t.Error("Expected empty trace for nil")
+	}
+
+	if trace := Trace(errors.New("plain")); trace != "plain\n" {
+		t.Errorf("Unexpected trace %q", trace)
+	}
+}
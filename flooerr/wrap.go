@@ -0,0 +1,45 @@
+package flooerr
+
+import (
+	"core-common-go/flooerr/internal"
+	"fmt"
+)
+
+// WrapIf wraps err with message, or returns nil when err is nil.
+func WrapIf(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return internal.Create().BuildSkip(0, err, message)
+}
+
+// WrapIfF wraps err with a formatted message, or returns nil when err is nil.
+func WrapIfF(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return internal.Create().BuildSkip(0, err, fmt.Sprintf(format, args...))
+}
+
+// Annotate wraps *errp with a formatted message when it is non-nil. It is
+// meant to be deferred with a named error result:
+//
+//	func (s *Service) Get(id string) (user *User, err error) {
+//		defer flooerr.Annotate(&err, "get user %s", id)
+//		...
+//	}
+func Annotate(errp *error, format string, args ...any) {
+	if errp == nil || *errp == nil {
+		return
+	}
+	*errp = internal.Create().BuildSkip(0, *errp, fmt.Sprintf(format, args...))
+}
+
+// SetStrictNilWrap makes Wrap, WrapF and the builder Wrap/Wrapf methods
+// panic when called with a nil cause, and returns the previous setting.
+// It is intended for tests:
+//
+//	defer flooerr.SetStrictNilWrap(flooerr.SetStrictNilWrap(true))
+func SetStrictNilWrap(enabled bool) bool {
+	return internal.SetStrictNilWrap(enabled)
+}
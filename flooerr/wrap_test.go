@@ -0,0 +1,142 @@
+package flooerr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWrapIf(t *testing.T) {
+	if err := WrapIf(nil, "context"); err != nil {
+		t.Errorf("Expected nil for nil cause, got %v", err)
+	}
+
+	cause := errors.New("original error")
+	err := WrapIf(cause, "context")
+	if err == nil {
+		t.Fatal("Expected non-nil error")
+	}
+
+	if !errors.Is(err, cause) {
+		t.Error("Expected wrapped error to match cause")
+	}
+
+	if err.Error() != "context; caused by: original error" {
+		t.Errorf("Unexpected error message '%s'", err.Error())
+	}
+}
+
+func TestWrapIfF(t *testing.T) {
+	if err := WrapIfF(nil, "load %s", "config"); err != nil {
+		t.Errorf("Expected nil for nil cause, got %v", err)
+	}
+
+	err := WrapIfF(errors.New("missing"), "load %s", "config")
+	if err == nil || !strings.HasPrefix(err.Error(), "load config") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestWrapIf_Builder(t *testing.T) {
+	builder := Message("load failed").WithCode("LOAD_ERR")
+	if err := builder.WrapIf(nil); err != nil {
+		t.Errorf("Expected nil for nil cause, got %v", err)
+	}
+
+	err := builder.WrapIf(errors.New("missing"))
+	if GetCodeString(err) != "LOAD_ERR" {
+		t.Errorf("Expected code 'LOAD_ERR', got '%s'", GetCodeString(err))
+	}
+
+	if GetMessage(err) != "load failed" {
+		t.Errorf("Expected message 'load failed', got '%s'", GetMessage(err))
+	}
+}
+
+func TestWrap_NilCause_NotStrict(t *testing.T) {
+	err := Wrap(nil, "message")
+	if err == nil {
+		t.Fatal("Expected Wrap(nil) to create an error outside strict mode")
+	}
+}
+
+func TestSetStrictNilWrap(t *testing.T) {
+	defer SetStrictNilWrap(SetStrictNilWrap(true))
+
+	calls := map[string]func(){
+		"Wrap":           func() { _ = Wrap(nil, "m") },
+		"WrapF":          func() { _ = WrapF(nil, "m %d", 1) },
+		"ErrProps.Wrap":  func() { _ = Message("m").Wrap(nil, "m") },
+		"ErrProps.Wrapf": func() { _ = Message("m").Wrapf(nil, "m %d", 1) },
+	}
+
+	for name, call := range calls {
+		func() {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Errorf("%s: expected panic in strict mode", name)
+					return
+				}
+				if !strings.Contains(r.(string), name) {
+					t.Errorf("%s: panic should name the call, got %v", name, r)
+				}
+			}()
+			call()
+		}()
+	}
+
+	// Creating errors without a cause stays allowed.
+	_ = Message("m").Error(nil, "m")
+	_ = Error("m")
+	if err := WrapIf(nil, "m"); err != nil {
+		t.Error("Expected WrapIf to return nil in strict mode")
+	}
+}
+
+func annotated(fail bool) (err error) {
+	defer Annotate(&err, "annotated %s", "op")
+	if fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestAnnotate(t *testing.T) {
+	if err := annotated(false); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+
+	err := annotated(true)
+	if err == nil {
+		t.Fatal("Expected non-nil error")
+	}
+
+	if err.Error() != "annotated op; caused by: failed" {
+		t.Errorf("Unexpected error message '%s'", err.Error())
+	}
+
+	stackTrace := GetStackTrace(err)
+	if len(stackTrace) > 0 && !strings.HasSuffix(stackTrace[0].Function, "annotated") {
+		t.Errorf("Expected first frame in annotated, got '%s'", stackTrace[0].Function)
+	}
+}
+
+// Annotate used to skip one frame too many and start the stack trace at the
+// caller of the annotated function.
+func TestAnnotate_StackStart(t *testing.T) {
+	stackTrace := GetStackTrace(annotated(true))
+	if len(stackTrace) < 2 {
+		t.Fatalf("Expected a stack trace, got %v", stackTrace)
+	}
+	if stackTrace[0].Function != "core-common-go/flooerr.annotated" {
+		t.Errorf("Expected the stack to start in annotated, got '%s'", stackTrace[0].Function)
+	}
+	if stackTrace[1].Function != "core-common-go/flooerr.TestAnnotate_StackStart" {
+		t.Errorf("Expected the test as second frame, got '%s'", stackTrace[1].Function)
+	}
+}
+
+func TestAnnotate_NilPointer(t *testing.T) {
+	Annotate(nil, "no-op")
+}